@@ -0,0 +1,42 @@
+package envflagparser
+
+import "reflect"
+
+// isAtomic reports whether a type is a typed wrapper of sync/atomic like atomic.Int64, atomic.Bool
+// or atomic.Pointer[T], which is set through its Store method.
+func isAtomic(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.PkgPath() != "sync/atomic" {
+		return false
+	}
+	ptr := reflect.PointerTo(typ)
+	load, hasLoad := ptr.MethodByName("Load")
+	_, hasStore := ptr.MethodByName("Store")
+	// atomic.Value holding any type is not supported.
+	return hasLoad && hasStore && load.Type.NumOut() == 1 && load.Type.Out(0).Kind() != reflect.Interface
+}
+
+// atomicValueType returns the type of the value held by an atomic wrapper, e.g. int64 for atomic.Int64.
+func atomicValueType(typ reflect.Type) reflect.Type {
+	load, _ := reflect.PointerTo(typ).MethodByName("Load")
+	return load.Type.Out(0)
+}
+
+// storeAtomic parses a value into the type held by an addressable atomic wrapper and stores it.
+func storeAtomic(field reflect.Value, tag reflect.StructTag, value string, rounding RoundingMode) error {
+	held := reflect.New(atomicValueType(field.Type())).Elem()
+	if err := setRoundedValue(held, tag, value, rounding); err != nil {
+		return err
+	}
+	field.Addr().MethodByName("Store").Call([]reflect.Value{held})
+	return nil
+}
+
+// loadAtomic returns the value held by an atomic wrapper.
+func loadAtomic(field reflect.Value) reflect.Value {
+	if !field.CanAddr() {
+		copied := reflect.New(field.Type()).Elem()
+		copied.Set(field)
+		field = copied
+	}
+	return field.Addr().MethodByName("Load").Call(nil)[0]
+}
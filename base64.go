@@ -0,0 +1,43 @@
+package envflagparser
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DecodeBase64JSON decodes a base64-encoded JSON object into the provided struct, e.g. structured config
+// passed through systems that only allow simple string environment variables. Fields are matched by
+// their json tags, flags, environment variables and default values are not considered.
+func DecodeBase64JSON(configStruct interface{}, encoded string) error {
+	return decodeBase64JSON(encoded, configStruct)
+}
+
+// setBase64JSON sets a field tagged format:"base64json" from base64-encoded JSON.
+func setBase64JSON(field reflect.Value, encoded string) error {
+	ptr := reflect.New(field.Type())
+	if err := decodeBase64JSON(encoded, ptr.Interface()); err != nil {
+		return err
+	}
+	field.Set(ptr.Elem())
+	return nil
+}
+
+// decodeBase64JSON decodes standard or URL-safe base64, padded or not, and unmarshals the JSON into target.
+func decodeBase64JSON(encoded string, target interface{}) error {
+	encoded = strings.TrimSpace(encoded)
+	encoding := base64.StdEncoding
+	if strings.ContainsAny(encoded, "-_") {
+		encoding = base64.URLEncoding
+	}
+	data, err := encoding.WithPadding(base64.NoPadding).DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return fmt.Errorf("invalid base64: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("invalid JSON in base64 value: %w", err)
+	}
+	return nil
+}
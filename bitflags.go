@@ -0,0 +1,79 @@
+package envflagparser
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// bitFlag is a named bit of the bitflags tag.
+type bitFlag struct {
+	name string
+	bits uint64
+}
+
+// bitFlags returns the named bits of the comma-separated name=bits pairs of the bitflags tag, e.g. read=1,write=2.
+func bitFlags(tag reflect.StructTag) ([]bitFlag, error) {
+	var flags []bitFlag
+	for _, pair := range strings.Split(tag.Get("bitflags"), ",") {
+		name, bits, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		value, err := strconv.ParseUint(strings.TrimSpace(bits), 0, 64)
+		if !ok || name == "" || err != nil {
+			return nil, fmt.Errorf("invalid bitflags tag %q", tag.Get("bitflags"))
+		}
+		flags = append(flags, bitFlag{name: strings.TrimSpace(name), bits: value})
+	}
+	return flags, nil
+}
+
+// parseBitFlags ORs the bits of a comma-separated list of names of the bitflags tag, e.g. read,exec to 5.
+func parseBitFlags(tag reflect.StructTag, value string) (string, error) {
+	flags, err := bitFlags(tag)
+	if err != nil {
+		return "", err
+	}
+
+	var result uint64
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		found := false
+		for _, flag := range flags {
+			if strings.EqualFold(flag.name, name) {
+				result |= flag.bits
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, len(flags))
+			for i, flag := range flags {
+				names[i] = flag.name
+			}
+			return "", fmt.Errorf("unknown flag %s, expected one of %s", name, strings.Join(names, ", "))
+		}
+	}
+	return strconv.FormatUint(result, 10), nil
+}
+
+// formatBitFlags formats bits as the comma-separated names of the bitflags tag, the reverse of parseBitFlags.
+func formatBitFlags(tag reflect.StructTag, bits uint64) (string, error) {
+	flags, err := bitFlags(tag)
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for _, flag := range flags {
+		if flag.bits != 0 && bits&flag.bits == flag.bits {
+			names = append(names, flag.name)
+			bits &^= flag.bits
+		}
+	}
+	if bits != 0 {
+		return "", fmt.Errorf("bits %#x are not named in the bitflags tag", bits)
+	}
+	return strings.Join(names, ","), nil
+}
@@ -0,0 +1,44 @@
+package envflagparser
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// byteUnits holds the number of bytes of the byte size units by lower-case name, decimal like MB and binary like MiB.
+var byteUnits = map[string]int64{
+	"b":  1,
+	"kb": 1e3, "mb": 1e6, "gb": 1e9, "tb": 1e12, "pb": 1e15,
+	"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40, "pib": 1 << 50,
+}
+
+// parseByteSize parses a byte size like 10MB, 1.5GiB or 2.5e6 into a whole number of bytes for integer fields
+// tagged bytesize. Units are case-insensitive, plain numbers in decimal or scientific notation are bytes.
+func parseByteSize(value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	i := len(trimmed)
+	for i > 0 && (trimmed[i-1] >= 'a' && trimmed[i-1] <= 'z' || trimmed[i-1] >= 'A' && trimmed[i-1] <= 'Z') {
+		i--
+	}
+	number, unit := strings.TrimSpace(trimmed[:i]), strings.ToLower(trimmed[i:])
+
+	scale := int64(1)
+	if unit != "" {
+		var ok bool
+		if scale, ok = byteUnits[unit]; !ok {
+			return "", fmt.Errorf("invalid byte size unit %q, expected B, KB, MB, GB, TB, PB or KiB, MiB, GiB, TiB, PiB", trimmed[i:])
+		}
+	}
+
+	// Rationals keep decimal fractions like 1.1 exact, unlike floats.
+	size, ok := new(big.Rat).SetString(number)
+	if !ok || strings.Contains(number, "/") {
+		return "", fmt.Errorf("invalid byte size %q, expected a number with an optional unit like 10MB, 1.5GiB or 2.5e6", value)
+	}
+	size.Mul(size, new(big.Rat).SetInt64(scale))
+	if !size.IsInt() {
+		return "", fmt.Errorf("byte size %s is not a whole number of bytes", value)
+	}
+	return size.Num().String(), nil
+}
@@ -0,0 +1,83 @@
+package envflagparser
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BashCompletion returns a bash completion script for the flags of the provided struct, completing the
+// flag names of the program os.Args[0], e.g. sourced by eval "$(myapp -completion bash)".
+// Flags tagged hiddenflag are omitted. It returns an empty string if the flags cannot be registered.
+func BashCompletion(config interface{}) string {
+	program, flags := completionFlags(config)
+	if flags == nil {
+		return ""
+	}
+
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = "-" + f.Name
+	}
+	function := "_" + completionIdentifier(program) + "_completions"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s\n", program)
+	fmt.Fprintf(&b, "%s() {\n", function)
+	fmt.Fprintf(&b, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(&b, "\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintf(&b, "}\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", function, program)
+	return b.String()
+}
+
+// ZshCompletion returns a zsh completion script for the flags of the provided struct with their usage
+// as descriptions, e.g. written to _myapp in a directory of fpath. Flags other than bool flags complete
+// a value. Flags tagged hiddenflag are omitted. It returns an empty string if the flags cannot be registered.
+func ZshCompletion(config interface{}) string {
+	program, flags := completionFlags(config)
+	if flags == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n\n_arguments", program)
+	for _, f := range flags {
+		description := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`).Replace(f.Usage)
+		spec := fmt.Sprintf("-%s[%s]", f.Name, description)
+		if !isBoolFlag(f.Value) {
+			spec += ":" + f.Name + ":"
+		}
+		fmt.Fprintf(&b, " \\\n\t'%s'", spec)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// completionFlags returns the program name and the visible flags of the provided struct sorted by name,
+// nil if the flags cannot be registered.
+func completionFlags(config interface{}) (string, []*flag.Flag) {
+	fs, err := FlagSet(config)
+	if err != nil {
+		return "", nil
+	}
+	flags := []*flag.Flag{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if ff, ok := f.Value.(*fieldFlag); !ok || !ff.hidden() {
+			flags = append(flags, f)
+		}
+	})
+	return filepath.Base(os.Args[0]), flags
+}
+
+// completionIdentifier replaces the characters of a program name not allowed in shell function names.
+func completionIdentifier(program string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, program)
+}
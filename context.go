@@ -0,0 +1,24 @@
+package envflagparser
+
+import "context"
+
+// configContextKey is the context key of the config stored by WithConfig.
+type configContextKey struct{}
+
+// WithConfig returns a copy of ctx carrying the provided config, e.g. a pointer to a parsed struct,
+// for request-scoped access with FromContext.
+func WithConfig(ctx context.Context, config interface{}) context.Context {
+	return context.WithValue(ctx, configContextKey{}, config)
+}
+
+// FromContext returns the config of type T stored in ctx by WithConfig, false if ctx carries none of that type.
+// A config stored by value is returned as a pointer to a copy.
+func FromContext[T any](ctx context.Context) (*T, bool) {
+	switch config := ctx.Value(configContextKey{}).(type) {
+	case *T:
+		return config, config != nil
+	case T:
+		return &config, true
+	}
+	return nil, false
+}
@@ -0,0 +1,36 @@
+package envflagparser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// setCSV sets a [][]string field tagged format:"csv" from newline-separated CSV rows. Quoted fields may
+// contain commas, quotes escaped as "" and newlines. Rows must have equal column counts unless AllowRaggedRows is set.
+func setCSV(field reflect.Value, value string) error {
+	if field.Type() != reflect.TypeOf([][]string(nil)) {
+		return fmt.Errorf("format csv requires a [][]string field, got %s", field.Type())
+	}
+	reader := csv.NewReader(strings.NewReader(value))
+	if AllowRaggedRows {
+		reader.FieldsPerRecord = -1
+	}
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("invalid csv: %w", err)
+	}
+	field.Set(reflect.ValueOf(rows))
+	return nil
+}
+
+// formatCSV renders rows as CSV parsed back by setCSV, quoting fields where needed.
+func formatCSV(rows [][]string) (string, error) {
+	var b strings.Builder
+	writer := csv.NewWriter(&b)
+	if err := writer.WriteAll(rows); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
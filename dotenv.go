@@ -0,0 +1,75 @@
+package envflagparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DotenvInlineComments defines whether an unquoted # after a value in dotenv files starts a comment,
+// e.g. PORT=8080 # note. Values containing a # like URLs with a fragment must then be quoted.
+var DotenvInlineComments = false
+
+// readDotenvFile reads the KEY=VALUE pairs of a dotenv file.
+func readDotenvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values, err := readDotenv(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// readDotenv reads KEY=VALUE pairs, one per line.
+// Empty lines and lines starting with # are skipped, an export prefix is allowed
+// and values may be enclosed in matching quotes, followed by a comment if DotenvInlineComments is set.
+func readDotenv(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+		value = strings.TrimSpace(value)
+		if DotenvInlineComments {
+			value = stripInlineComment(value)
+		}
+		values[key] = unquote(value)
+	}
+
+	return values, scanner.Err()
+}
+
+// stripInlineComment removes a trailing # comment from a dotenv value. A # inside a quoted value is kept.
+func stripInlineComment(value string) string {
+	if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+		end := strings.IndexByte(value[1:], value[0])
+		if end == -1 {
+			return value
+		}
+		quoted, rest := value[:end+2], strings.TrimSpace(value[end+2:])
+		if rest == "" || strings.HasPrefix(rest, "#") {
+			return quoted
+		}
+		return value
+	}
+	if i := strings.IndexByte(value, '#'); i != -1 {
+		return strings.TrimSpace(value[:i])
+	}
+	return value
+}
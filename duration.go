@@ -0,0 +1,33 @@
+package envflagparser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// parseDuration parses a duration like 1m30s. If unit is set, e.g. by durationunit:"s", plain numbers
+// in decimal or scientific notation like 90, 1.5 or 1.5e3 are interpreted in that unit.
+func parseDuration(value, unit string) (time.Duration, error) {
+	if unit == "" {
+		return time.ParseDuration(value)
+	}
+	scale, err := time.ParseDuration("1" + unit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration unit %q", unit)
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d, nil
+		}
+		return 0, fmt.Errorf("invalid duration %q, expected a number of %s like 1.5e3 or a duration like 1m30s", value, unit)
+	}
+	scaled := number * float64(scale)
+	if math.IsNaN(scaled) || math.Abs(scaled) >= math.MaxInt64 {
+		return 0, fmt.Errorf("duration %s%s out of range", value, unit)
+	}
+	return time.Duration(math.Round(scaled)), nil
+}
@@ -0,0 +1,219 @@
+package envflagparser
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// UnquoteEnvValues defines whether matching surrounding quotes are stripped from environment variable values,
+// e.g. PORT="8080" from orchestrators quoting all values.
+var UnquoteEnvValues = false
+
+// CaseInsensitiveEnv defines whether environment variable names are matched case-insensitively.
+// It is enabled by default on Windows, where environment variable names are case-insensitive.
+var CaseInsensitiveEnv = runtime.GOOS == "windows"
+
+// FileEnvSuffix defines the suffix of environment variables holding the path of a file to read the value of a field
+// from, like Docker secrets, e.g. DB_PASSWORD_FILE for DB_PASSWORD with the suffix _FILE. It is only read if the
+// variable itself and its aliases are absent. A trailing newline of the file is stripped. Disabled if empty.
+var FileEnvSuffix = ""
+
+// ApplyEnv applies environment variables onto an already populated struct.
+// Fields whose environment variable is absent keep their current value,
+// flags and default values are not considered.
+func ApplyEnv(configStruct interface{}) error {
+	fields, err := collectFields(reflect.ValueOf(configStruct).Elem())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if f.Tag.Get("catchall") == "true" {
+			continue
+		}
+		if isIndexedStruct(f.Tag) {
+			if err := setIndexedStructs(f); err != nil {
+				return err
+			}
+			continue
+		}
+
+		envValue, envExists, err := lookupFieldEnv(f)
+		if err != nil {
+			return err
+		}
+		if !envExists || (envValue == "" && isEmptyDefault(f.Tag)) || isUnsetSentinel(envValue) {
+			continue
+		}
+
+		if err := setFieldValue(f, envValue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckRequired returns the environment variable names of required fields that are currently absent,
+// e.g. for preflight checks before launching an application. Flags are neither registered nor parsed.
+// Required fields without an environment variable are not reported.
+func CheckRequired(configStruct interface{}) []string {
+	fields, err := collectFields(reflect.Indirect(reflect.ValueOf(configStruct)))
+	if err != nil {
+		return nil
+	}
+
+	var missing []string
+	for _, f := range fields {
+		if !isRequired(f.Tag) || f.EnvKey == "" {
+			continue
+		}
+		envValue, envExists, err := lookupFieldEnv(f)
+		if err != nil || !envExists || (envValue == "" && isEmptyDefault(f.Tag)) {
+			missing = append(missing, f.EnvKey)
+		}
+	}
+	return missing
+}
+
+// EnvKeys returns the names of the environment variables read for the fields of the provided struct,
+// with prefixes of nested structs applied and followed by their deprecated aliases and FileEnvSuffix variant,
+// e.g. to check deployment manifests. Catch-all fields, reading variables by prefix, are not included.
+func EnvKeys(configStruct interface{}) []string {
+	fields, err := collectFields(reflect.Indirect(reflect.ValueOf(configStruct)))
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	for _, f := range fields {
+		if f.EnvKey == "" || f.Tag.Get("catchall") == "true" {
+			continue
+		}
+		keys = append(keys, f.EnvKey)
+		keys = append(keys, envAliases(f.Tag)...)
+		if FileEnvSuffix != "" {
+			keys = append(keys, f.EnvKey+FileEnvSuffix)
+		}
+	}
+	return keys
+}
+
+// lookupFieldEnv retrieves the environment variable of a field.
+// If it is absent, the deprecated aliases of the envalias tag are tried in order, warning about the alias in use,
+// then the file named by its FileEnvSuffix variant.
+// For fields tagged indirect, the variable holds the name of the variable to read instead.
+func lookupFieldEnv(f configField) (string, bool, error) {
+	if f.EnvKey == "" {
+		return "", false, nil
+	}
+
+	value, ok := lookupEnv(f.EnvPrefix + f.EnvKey)
+	if !ok {
+		value, ok = lookupEnvAlias(f)
+	}
+	if !ok && FileEnvSuffix != "" {
+		var err error
+		if value, ok, err = lookupEnvFile(f); err != nil {
+			return "", false, err
+		}
+	}
+	if !ok || f.Tag.Get("indirect") != "true" {
+		return value, ok, nil
+	}
+
+	indirectValue, ok := lookupEnv(value)
+	if !ok {
+		return "", false, fmt.Errorf("environment variable %s referenced by %s of field %s is not set", value, f.EnvPrefix+f.EnvKey, f.Path)
+	}
+	return indirectValue, true, nil
+}
+
+// lookupEnvFile retrieves the content of the file named by the FileEnvSuffix variant of the environment variable
+// of a field.
+func lookupEnvFile(f configField) (string, bool, error) {
+	name := f.EnvPrefix + f.EnvKey + FileEnvSuffix
+	path, ok := lookupEnv(name)
+	if !ok {
+		return "", false, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("read file %s of %s for field %s: %w", path, name, f.Path, err)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(content), "\n"), "\r"), true, nil
+}
+
+// lookupEnvAlias retrieves the first set environment variable of the envalias tag of a field.
+func lookupEnvAlias(f configField) (string, bool) {
+	for _, alias := range envAliases(f.Tag) {
+		if value, ok := lookupEnv(f.EnvPrefix + alias); ok {
+			warnf("environment variable %s of field %s is deprecated, use %s instead", f.EnvPrefix+alias, f.Path, f.EnvPrefix+f.EnvKey)
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// envAliases returns the deprecated environment variable names of the comma-separated envalias tag.
+func envAliases(tag reflect.StructTag) []string {
+	var aliases []string
+	for _, alias := range strings.Split(tag.Get("envalias"), ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// claimEnv marks the environment variable of a field and its aliases as read, excluding them from catch-all fields.
+func claimEnv(claimedEnv map[string]bool, f configField) {
+	if f.EnvKey == "" {
+		return
+	}
+	claimedEnv[f.EnvPrefix+f.EnvKey] = true
+	for _, alias := range envAliases(f.Tag) {
+		claimedEnv[f.EnvPrefix+alias] = true
+	}
+	if FileEnvSuffix != "" {
+		claimedEnv[f.EnvPrefix+f.EnvKey+FileEnvSuffix] = true
+	}
+}
+
+// lookupEnv retrieves the value of an environment variable, unquoted if UnquoteEnvValues is set.
+func lookupEnv(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	if !ok && CaseInsensitiveEnv {
+		value, ok = lookupEnvFold(key)
+	}
+	if ok && UnquoteEnvValues {
+		value = unquote(value)
+	}
+	return value, ok
+}
+
+// lookupEnvFold retrieves the value of an environment variable ignoring the case of its name.
+func lookupEnvFold(key string) (string, bool) {
+	for _, entry := range environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if strings.EqualFold(name, key) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// environ returns the environment as key=value entries like os.Environ, skipping the pseudo-variables
+// like =C:=C:\dir holding the current directory of Windows drives.
+func environ() []string {
+	var entries []string
+	for _, entry := range os.Environ() {
+		if !strings.HasPrefix(entry, "=") {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,46 @@
+package envflagparser
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// DefaultErrorCode defines the Code of a ParseError for fields without an errcode tag.
+var DefaultErrorCode = 1
+
+// ParseError is the error of a field that failed to parse or validate, or of a required field that was
+// not provided. Programs mapping config errors to exit codes retrieve it with errors.As, e.g.
+// os.Exit(parseErr.Code) with the code of the errcode tag of the field.
+type ParseError struct {
+	// Field is the dotted path of the field, e.g. Database.Port.
+	Field string
+	// Code is the errcode tag of the field, DefaultErrorCode if it has none.
+	Code int
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the message of the underlying error.
+func (e *ParseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// fieldError wraps the error of a field into a ParseError with the code of its errcode tag.
+func fieldError(f configField, err error) error {
+	code, ok := errorCode(f.Tag)
+	if !ok {
+		code = DefaultErrorCode
+	}
+	return &ParseError{Field: f.Path, Code: code, Err: err}
+}
+
+// errorCode returns the integer of the errcode tag of a field, false if it has none or it is invalid.
+func errorCode(tag reflect.StructTag) (int, bool) {
+	code, err := strconv.Atoi(tag.Get("errcode"))
+	return code, err == nil
+}
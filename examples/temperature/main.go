@@ -0,0 +1,53 @@
+// Temperature shows a numeric type with units parsed by a parser registered with RegisterParser,
+// e.g. TARGET_TEMP=77F or -target 298.15K, stored in degrees Celsius.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+// Temperature is a temperature in degrees Celsius.
+type Temperature float64
+
+// parseTemperature parses a temperature with the unit C, F or K, plain numbers are degrees Celsius.
+func parseTemperature(value string) (Temperature, error) {
+	number := strings.TrimRightFunc(value, unicode.IsLetter)
+	degrees, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid temperature %q, expected a number with the unit C, F or K", value)
+	}
+	switch unit := value[len(number):]; unit {
+	case "", "C":
+		return Temperature(degrees), nil
+	case "F":
+		return Temperature((degrees - 32) * 5 / 9), nil
+	case "K":
+		return Temperature(degrees - 273.15), nil
+	default:
+		return 0, fmt.Errorf("unknown temperature unit %q", unit)
+	}
+}
+
+type ClimateConfig struct {
+	Target  Temperature `env:"TARGET_TEMP" flag:"target" default:"21C" usage:"Target temperature"`
+	Maximum Temperature `env:"MAX_TEMP" flag:"max" default:"86F" usage:"Maximum temperature"`
+}
+
+func main() {
+	envflagparser.RegisterParser(parseTemperature)
+
+	config := &ClimateConfig{}
+	if err := envflagparser.ParseConfig(config); err != nil {
+		fmt.Fprintf(os.Stderr, "error parsing config: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Target: %.1f°C\n", config.Target)
+	fmt.Printf("Maximum: %.1f°C\n", config.Maximum)
+}
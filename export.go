@@ -0,0 +1,49 @@
+package envflagparser
+
+import (
+	"encoding"
+	"reflect"
+	"time"
+)
+
+// ToMap returns the values of the provided struct keyed by field name, e.g. for templating after a parse.
+// Nested structs produce nested maps, unexported fields are skipped.
+func ToMap(configStruct interface{}) map[string]interface{} {
+	return structToMap(reflect.Indirect(reflect.ValueOf(configStruct)))
+}
+
+// structToMap converts a struct value into a map keyed by field name.
+func structToMap(elem reflect.Value) map[string]interface{} {
+	typ := elem.Type()
+	values := make(map[string]interface{}, elem.NumField())
+	for i := 0; i < elem.NumField(); i++ {
+		if !typ.Field(i).IsExported() {
+			continue
+		}
+		field := elem.Field(i)
+		if isNestedStruct(field) {
+			values[typ.Field(i).Name] = structToMap(reflect.Indirect(field))
+		} else if isAtomic(field.Type()) {
+			values[typ.Field(i).Name] = loadAtomic(field).Interface()
+		} else {
+			values[typ.Field(i).Name] = field.Interface()
+		}
+	}
+	return values
+}
+
+// isNestedStruct reports whether a field holds a nested config struct, i.e. a struct or a non-nil
+// pointer to a struct that neither marshals itself as text like time.Time nor is an atomic wrapper.
+func isNestedStruct(field reflect.Value) bool {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return false
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.Struct || isAtomic(field.Type()) || field.Type() == reflect.TypeOf(time.Location{}) {
+		return false
+	}
+	_, isTextMarshaler := reflect.New(field.Type()).Interface().(encoding.TextMarshaler)
+	return !isTextMarshaler
+}
@@ -0,0 +1,180 @@
+package envflagparser
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// configField is a field of the config struct or of one of its nested structs.
+type configField struct {
+	reflect.StructField
+	// Value is the value of the field.
+	Value reflect.Value
+	// Path is the dotted path of the field from the config struct, e.g. Database.Host.
+	Path string
+	// EnvKey is the environment variable name of the field with the prefixes of enclosing structs applied.
+	EnvKey string
+	// EnvPrefix is prepended to EnvKey and the envalias names when looking up the environment, e.g. by
+	// ParseConfigStripPrefix. Dotenv keys, references and catch-all keys use the names without it.
+	EnvPrefix string
+	// Rounding is the rounding of floats coerced to the field by LenientTypes if it has no rounding tag,
+	// e.g. set by Options.Rounding. RoundDefault selects FloatRounding.
+	Rounding RoundingMode
+	// Struct is the type of the struct declaring the field.
+	Struct reflect.Type
+}
+
+// EnvKeyTemplate defines a text/template computing the environment variable name of each field, e.g.
+// SVC_{{.Field}}_VALUE reading SVC_MAX_CONNS_VALUE for the field MaxConns. The template replaces the
+// prefix and env tag rules, except for absolute and disabled env tags. It is disabled if empty.
+var EnvKeyTemplate = ""
+
+// collectFields returns the fields of a struct value, descending into nested structs.
+//
+// Nested structs are struct fields without env and flag tags. The prefix tag of a nested struct is
+// prepended to the environment variable names of its fields, unless a field's env tag starts with /,
+// which makes the name absolute. Fields of a prefixed struct without an env tag derive their
+// environment variable name from the field name, e.g. prefix:"DB_" and field MaxConns read DB_MAX_CONNS.
+// An env tag of - disables the environment variable of a field.
+func collectFields(elem reflect.Value) ([]configField, error) {
+	var tmpl *template.Template
+	if EnvKeyTemplate != "" {
+		var err error
+		if tmpl, err = template.New("env").Option("missingkey=error").Parse(EnvKeyTemplate); err != nil {
+			return nil, fmt.Errorf("invalid env key template: %w", err)
+		}
+	}
+	return appendFields(nil, elem, "", "", false, tmpl)
+}
+
+// appendFields appends the fields of the struct value elem to fields.
+func appendFields(fields []configField, elem reflect.Value, path, prefix string, prefixed bool, tmpl *template.Template) ([]configField, error) {
+	typ := elem.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		fieldPath := fieldType.Name
+		if path != "" {
+			fieldPath = path + "." + fieldType.Name
+		}
+
+		envKey := envTag(fieldType.Tag)
+		nestedPrefix, hasPrefix := fieldType.Tag.Lookup("prefix")
+
+		if isNestedStructType(fieldType.Type) && envKey == "" && flagTag(fieldType.Tag) == "" {
+			var err error
+			fields, err = appendFields(fields, elem.Field(i), fieldPath, prefix+nestedPrefix, prefixed || hasPrefix, tmpl)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Channels, functions and unsafe pointers cannot be parsed, untagged ones are skipped.
+		if isUnparsableKind(fieldType.Type.Kind()) && (envKey == "" || envKey == "-") && flagTag(fieldType.Tag) == "" {
+			continue
+		}
+
+		if hasPrefix {
+			if isNestedStructType(fieldType.Type) {
+				return nil, fmt.Errorf("field %s has a prefix and an env or flag tag, it is ambiguous whether it is a nested struct", fieldPath)
+			}
+			return nil, fmt.Errorf("field %s has a prefix but is not a nested struct", fieldPath)
+		}
+
+		switch {
+		case envKey == "-" || isIndexedStruct(fieldType.Tag):
+			envKey = ""
+		case envKey == "/":
+			return nil, fmt.Errorf("field %s has an empty absolute env tag", fieldPath)
+		case strings.HasPrefix(envKey, "/"):
+			envKey = envKey[1:]
+		case tmpl != nil && fieldType.Tag.Get("catchall") != "true":
+			var err error
+			if envKey, err = executeEnvKeyTemplate(tmpl, fieldType, fieldPath, prefix, envKey); err != nil {
+				return nil, err
+			}
+		case envKey != "":
+			envKey = prefix + envKey
+		case prefixed:
+			envKey = prefix + toEnvName(fieldType.Name)
+		}
+
+		fields = append(fields, configField{
+			StructField: fieldType,
+			Value:       elem.Field(i),
+			Path:        fieldPath,
+			EnvKey:      envKey,
+			Struct:      typ,
+		})
+	}
+	return fields, nil
+}
+
+// envKeyTemplateData is the data of EnvKeyTemplate for a field.
+type envKeyTemplateData struct {
+	// Field is the field name as environment variable name, e.g. MAX_CONNS.
+	Field string
+	// Name is the Go field name, e.g. MaxConns.
+	Name string
+	// Path is the dotted path of the field, e.g. Database.MaxConns.
+	Path string
+	// Prefix is the combined prefix of the enclosing structs, e.g. DB_.
+	Prefix string
+	// Env is the env tag of the field, empty if it has none.
+	Env string
+	// Tag is the struct tag of the field, e.g. for {{.Tag.Get "toml"}}.
+	Tag reflect.StructTag
+}
+
+// executeEnvKeyTemplate computes the environment variable name of a field from EnvKeyTemplate.
+func executeEnvKeyTemplate(tmpl *template.Template, fieldType reflect.StructField, path, prefix, env string) (string, error) {
+	var b strings.Builder
+	err := tmpl.Execute(&b, envKeyTemplateData{
+		Field:  toEnvName(fieldType.Name),
+		Name:   fieldType.Name,
+		Path:   path,
+		Prefix: prefix,
+		Env:    env,
+		Tag:    fieldType.Tag,
+	})
+	if err != nil {
+		return "", fmt.Errorf("env key template of field %s: %w", path, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// isNestedStructType reports whether a type is a struct holding nested config fields,
+// i.e. a struct that is not parsed from a single value like time.Time.
+func isNestedStructType(typ reflect.Type) bool {
+	switch reflect.New(typ).Interface().(type) {
+	case StringSetter, encoding.TextUnmarshaler:
+		return false
+	}
+	return isNestedStruct(reflect.New(typ).Elem())
+}
+
+// isUnparsableKind reports whether fields of a kind cannot be parsed from a string, like channels.
+func isUnparsableKind(kind reflect.Kind) bool {
+	return kind == reflect.Chan || kind == reflect.Func || kind == reflect.UnsafePointer
+}
+
+// toEnvName converts a field name to an environment variable name, e.g. MaxConns to MAX_CONNS.
+func toEnvName(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
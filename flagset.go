@@ -0,0 +1,324 @@
+package envflagparser
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// BindFromFlagSet sets the fields of the provided struct from environment variables and the
+// flags of an already parsed flag.FlagSet, e.g. the one of a subcommand.
+// Flags are looked up by their flag tag instead of being registered. The default of a flag takes precedence
+// over the default tag of its field, unless it is empty or the zero value like 0 of flags defined without a
+// default, so pointer fields stay nil if neither provides a value.
+func BindFromFlagSet(configStruct interface{}, fs *flag.FlagSet) error {
+	fields, err := collectFields(reflect.ValueOf(configStruct).Elem())
+	if err != nil {
+		return err
+	}
+
+	// Flags explicitly set on the command line.
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	if err := validateTags(fields); err != nil {
+		return err
+	}
+
+	defaults, err := resolveDefaults(fields, nil)
+	if err != nil {
+		return err
+	}
+
+	fileValues, err := readEnvFile(EnvFile)
+	if err != nil {
+		return err
+	}
+	if err := checkEnvFileKeys(fields, fileValues, StrictEnvFile); err != nil {
+		return err
+	}
+	file := envFileSource(fileValues)
+
+	claimedEnv := make(map[string]bool)
+	var catchAllFields []int
+
+	for i, f := range fields {
+		if f.Tag.Get("catchall") == "true" {
+			catchAllFields = append(catchAllFields, i)
+			continue
+		}
+		if isIndexedStruct(f.Tag) {
+			if enabledStages()&StageEnv != 0 {
+				if err := setIndexedStructs(f); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		claimEnv(claimedEnv, f)
+
+		values, err := lookupStageValues(f, defaults[i], file)
+		if err != nil {
+			return err
+		}
+
+		if flagName := flagTag(f.Tag); flagName != "" {
+			if fsFlag := fs.Lookup(flagName); fsFlag != nil {
+				if !isZeroFlagDefault(f, fsFlag.DefValue) {
+					values[StageDefault] = fsFlag.DefValue
+				}
+				if setFlags[flagName] {
+					values[StageFlag] = fsFlag.Value.String()
+				}
+			}
+		}
+
+		value, stage := values.resolve(f.Tag, PrioritiseEnv)
+		if isRequired(f.Tag) && (stage == 0 || stage == StageDefault) {
+			return requiredError(f)
+		}
+		if fallback, ok := lookupFallback(f.Tag, value, stage); ok {
+			value, stage = fallback, StageDefault
+		}
+		if stage == 0 {
+			continue
+		}
+
+		if err := setFieldValue(f, value); err != nil {
+			return err
+		}
+	}
+
+	for _, i := range catchAllFields {
+		if err := setCatchAll(fields[i], claimedEnv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isZeroFlagDefault reports whether the default of a flag is empty or parses to the zero value of the field,
+// like the defaults of flags defined without one, e.g. 0 by fs.Int("port", 0, "").
+func isZeroFlagDefault(f configField, defValue string) bool {
+	if defValue == "" {
+		return true
+	}
+	value := reflect.New(f.Type).Elem()
+	if err := setValue(value, f.Tag, defValue); err != nil {
+		return false
+	}
+	return reflect.Indirect(value).IsZero()
+}
+
+// FlagSet returns a flag.FlagSet with the flags of the provided struct registered but not parsed,
+// e.g. to be introspected by shell completion libraries.
+func FlagSet(configStruct interface{}) (*flag.FlagSet, error) {
+	fields, err := collectFields(reflect.Indirect(reflect.ValueOf(configStruct)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateTags(fields); err != nil {
+		return nil, err
+	}
+
+	defaults, err := resolveDefaults(fields, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	for i, f := range fields {
+		flagName := flagTag(f.Tag)
+		if flagName == "" || f.Tag.Get("catchall") == "true" {
+			continue
+		}
+		if fs.Lookup(flagName) != nil {
+			return nil, fmt.Errorf("flag redefined: %s", flagName)
+		}
+		if err := checkFlagType(f); err != nil {
+			return nil, err
+		}
+
+		fs.Var(newFieldFlag(f, defaults[i]), flagName, usageTag(f.Tag))
+	}
+	hideFlags(fs)
+
+	return fs, nil
+}
+
+// reorderArgs moves the flags of args and their values before the positional arguments, which follow
+// a -- terminator. Arguments after a -- in args are positional.
+func reorderArgs(fs *flag.FlagSet, args []string) []string {
+	var flags, positionals []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positionals = append(positionals, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			positionals = append(positionals, arg)
+			continue
+		}
+		flags = append(flags, arg)
+
+		// Flags other than bool flags take the next argument as value, unless given as -name=value.
+		name := strings.TrimPrefix(arg[1:], "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := fs.Lookup(name); f != nil && !isBoolFlag(f.Value) && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	if len(positionals) == 0 {
+		return flags
+	}
+	return append(append(flags, "--"), positionals...)
+}
+
+// isBoolFlag reports whether a flag can be set without a value.
+func isBoolFlag(value flag.Value) bool {
+	boolFlag, ok := value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// fieldFlag is the flag.Value registered for a struct field.
+// It keeps the raw command-line value, which is validated against the field type when set.
+type fieldFlag struct {
+	typ    reflect.Type
+	tag    reflect.StructTag
+	value  string
+	setter FieldSetter
+	// valuePrefix is stripped from the values of string flags.
+	valuePrefix string
+	// rounding is the rounding mode of the field for floats coerced by LenientTypes.
+	rounding RoundingMode
+	// err is the error of the last value that failed to parse in Set.
+	err error
+}
+
+// checkFlagType rejects flags of fields with a kind that cannot be parsed from a value like complex128,
+// unless a registered setter or parser, a method of the type or the format and stdinaware tags parse it.
+func checkFlagType(f configField) error {
+	switch f.Type.Kind() {
+	case reflect.Complex64, reflect.Complex128, reflect.Array, reflect.Chan, reflect.Func, reflect.Interface, reflect.UnsafePointer:
+	default:
+		return nil
+	}
+	if lookupFieldSetter(f) != nil || lookupTypeParser(f.Type) != nil || f.Tag.Get("format") != "" || f.Tag.Get("stdinaware") == "true" {
+		return nil
+	}
+	switch reflect.New(f.Type).Interface().(type) {
+	case StringSetter, encoding.TextUnmarshaler:
+		return nil
+	}
+	return fmt.Errorf("unsupported type %s of flag %s", f.Type, flagTag(f.Tag))
+}
+
+// newFieldFlag creates a fieldFlag for a field holding the default value.
+func newFieldFlag(f configField, defaultValue string) *fieldFlag {
+	return &fieldFlag{typ: f.Type, tag: f.Tag, value: defaultValue, setter: lookupFieldSetter(f), rounding: f.Rounding}
+}
+
+// parse sets field from the transformed value with the registered field setter if any, otherwise based on its type.
+// Secret references are left unset, they are resolved once when the field is set.
+func (f *fieldFlag) parse(field reflect.Value, value string) error {
+	if isSecretRef(f.tag, value) {
+		return nil
+	}
+	value, err := transformValue(f.tag, value)
+	if err != nil {
+		return err
+	}
+	if f.setter != nil {
+		return f.setter(field, value)
+	}
+	return setRoundedValue(field, f.tag, value, f.rounding)
+}
+
+// hidden reports whether the flag is tagged hiddenflag, registered but omitted from usage output.
+func (f *fieldFlag) hidden() bool {
+	return f.tag.Get("hiddenflag") == "true"
+}
+
+// hideFlags replaces the usage of a flag set with one omitting hidden flags, if it has any,
+// as the flag package has no concept of hidden flags.
+func hideFlags(fs *flag.FlagSet) {
+	visible := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	hasHidden := false
+	fs.VisitAll(func(f *flag.Flag) {
+		if ff, ok := f.Value.(*fieldFlag); ok && ff.hidden() {
+			hasHidden = true
+			return
+		}
+		visible.Var(f.Value, f.Name, f.Usage)
+		visible.Lookup(f.Name).DefValue = f.DefValue
+	})
+	if !hasHidden {
+		return
+	}
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+		visible.SetOutput(fs.Output())
+		visible.PrintDefaults()
+	}
+}
+
+// String returns the raw flag value.
+func (f *fieldFlag) String() string {
+	return f.value
+}
+
+// Set validates the value against the field type and stores it.
+// The UnsetSentinel is stored without validation, the pipeline treats it as not provided.
+func (f *fieldFlag) Set(value string) error {
+	if isUnsetSentinel(value) {
+		f.value = value
+		return nil
+	}
+	if err := f.parse(reflect.New(f.typ).Elem(), value); err != nil {
+		f.err = err
+		return err
+	}
+	f.value = value
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be set without a value.
+func (f *fieldFlag) IsBoolFlag() bool {
+	if f.typ == nil {
+		return false
+	}
+	typ := f.typ
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if isAtomic(typ) {
+		typ = atomicValueType(typ)
+	}
+	return typ.Kind() == reflect.Bool
+}
+
+// fieldValue returns the value to set on the field. For string fields, the value prefix is stripped
+// and the value is unquoted if UnquoteFlagValues is set.
+func (f *fieldFlag) fieldValue() string {
+	if f.typ.Kind() != reflect.String {
+		return f.value
+	}
+	value := strings.TrimPrefix(f.value, f.valuePrefix)
+	if UnquoteFlagValues {
+		return unquote(value)
+	}
+	return value
+}
@@ -0,0 +1,69 @@
+package envflagparser
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// isIndexedStruct reports whether a field is tagged indexedstruct, collecting groups of numbered environment variables.
+func isIndexedStruct(tag reflect.StructTag) bool {
+	return tag.Get("indexedstruct") != ""
+}
+
+// setIndexedStructs sets a slice of structs tagged indexedstruct from groups of environment variables numbered
+// from 1, e.g. UPSTREAM_1_HOST and UPSTREAM_1_PORT for the first element with the tag indexedstruct:"UPSTREAM".
+// The fields of an element are named like those of a nested struct with the prefix UPSTREAM_1_.
+// Collecting stops at the first index without any variable set.
+func setIndexedStructs(f configField) error {
+	if f.Type.Kind() != reflect.Slice || !isNestedStructType(f.Type.Elem()) {
+		return fmt.Errorf("indexed struct field %s must be a slice of structs", f.Path)
+	}
+
+	group := f.Tag.Get("indexedstruct")
+	slice := reflect.MakeSlice(f.Type, 0, 0)
+	for i := 1; ; i++ {
+		elem := reflect.New(f.Type.Elem()).Elem()
+		elemPath := fmt.Sprintf("%s[%d]", f.Path, i-1)
+		fields, err := appendFields(nil, elem, elemPath, fmt.Sprintf("%s_%d_", group, i), true, nil)
+		if err != nil {
+			return err
+		}
+
+		values := make([]string, len(fields))
+		provided := make([]bool, len(fields))
+		found := false
+		for j, ef := range fields {
+			ef.EnvPrefix = f.EnvPrefix
+			values[j], provided[j], err = lookupFieldEnv(ef)
+			if err != nil {
+				return err
+			}
+			found = found || provided[j]
+		}
+		if !found {
+			break
+		}
+
+		for j, ef := range fields {
+			value := values[j]
+			if !provided[j] {
+				if isRequired(ef.Tag) {
+					return requiredError(ef)
+				}
+				if value = defaultTag(ef.Tag); value == "" {
+					continue
+				}
+			}
+			if err := setFieldValue(ef, value); err != nil {
+				return err
+			}
+		}
+		slice = reflect.Append(slice, elem)
+	}
+
+	// Like other fields, the field keeps its value if no variable is set.
+	if slice.Len() > 0 {
+		f.Value.Set(slice)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package envflagparser
+
+import (
+	"io"
+	"os"
+	"reflect"
+)
+
+// StdinPath is the path value of fields tagged stdinaware meaning stdin instead of a file.
+const StdinPath = "-"
+
+// inputFile is an io.ReadCloser of a file opened on the first read, so parsing has no side effects
+// and errors like a missing file surface when the program reads its input.
+type inputFile struct {
+	path string
+	file *os.File
+	err  error
+}
+
+// Read opens the file on the first call and reads from it.
+func (f *inputFile) Read(p []byte) (int, error) {
+	if f.file == nil && f.err == nil {
+		f.file, f.err = os.Open(f.path)
+	}
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.file.Read(p)
+}
+
+// Close closes the file if it was opened.
+func (f *inputFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+// MarshalText returns the path of the file.
+func (f *inputFile) MarshalText() ([]byte, error) {
+	return []byte(f.path), nil
+}
+
+// setInput sets an io.Reader or io.ReadCloser field tagged stdinaware to os.Stdin for StdinPath,
+// otherwise to the file at the path value. It reports false for other field types, like string
+// fields, which keep the path value to be compared against StdinPath.
+func setInput(field reflect.Value, value string) bool {
+	if !reflect.TypeOf((*inputFile)(nil)).AssignableTo(field.Type()) || field.Kind() != reflect.Interface {
+		return false
+	}
+	var reader io.ReadCloser = &inputFile{path: value}
+	if value == StdinPath {
+		reader = os.Stdin
+	}
+	field.Set(reflect.ValueOf(reader))
+	return true
+}
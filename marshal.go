@@ -0,0 +1,186 @@
+package envflagparser
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MarshalEnv renders the values of the provided struct as KEY=value lines of a dotenv file, keyed by
+// environment variable name, e.g. to preview or export a parsed config. Values are formatted to be parsed
+// back into the same struct: bools use the first word of their truevals or falsevals tag, slices and maps
+// are joined by their delimiter. Fields without an environment variable, nil pointers and catch-all fields are skipped.
+func MarshalEnv(configStruct interface{}) ([]byte, error) {
+	fields, err := collectFields(reflect.Indirect(reflect.ValueOf(configStruct)))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, f := range fields {
+		if f.EnvKey == "" || f.Tag.Get("catchall") == "true" {
+			continue
+		}
+		if f.Value.Kind() == reflect.Ptr && f.Value.IsNil() {
+			continue
+		}
+
+		value, err := formatEnvValue(f.Value, f.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Path, err)
+		}
+		if strings.ContainsAny(value, " \t#") {
+			value = `"` + value + `"`
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", f.EnvKey, value)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatEnvValue formats a value in the string form parsed by setValue.
+func formatEnvValue(value reflect.Value, tag reflect.StructTag) (string, error) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+	if isAtomic(value.Type()) {
+		value = loadAtomic(value)
+	}
+	if tag.Get("format") == "base64json" {
+		data, err := json.Marshal(value.Interface())
+		return base64.StdEncoding.EncodeToString(data), err
+	}
+	if mac, ok := value.Interface().(net.HardwareAddr); ok {
+		return mac.String(), nil
+	}
+	if value.Type() == reflect.TypeOf(os.FileMode(0)) {
+		return fmt.Sprintf("%#o", value.Uint()), nil
+	}
+	if value.Type() == reflect.TypeOf(time.Location{}) {
+		location := value.Interface().(time.Location)
+		return location.String(), nil
+	}
+	if value.Kind() == reflect.Interface && value.Interface() == os.Stdin {
+		return StdinPath, nil
+	}
+	if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if tag.Get("money") != "" {
+			return formatCents(value.Int()), nil
+		}
+		if _, ok := tag.Lookup("bitflags"); ok {
+			return formatBitFlags(tag, uint64(value.Int()))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, ok := tag.Lookup("bitflags"); ok {
+			return formatBitFlags(tag, value.Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		if sep := tag.Get("decimalsep"); sep != "" {
+			formatted := strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits())
+			return strings.Replace(formatted, ".", sep, 1), nil
+		}
+	case reflect.Bool:
+		name := "falsevals"
+		if value.Bool() {
+			name = "truevals"
+		}
+		if words := boolWords(tag, name); len(words) > 0 {
+			return words[0], nil
+		}
+	case reflect.Slice:
+		// Slices of pointers and interface{} are rendered as JSON arrays to keep nil elements and element types.
+		if kind := value.Type().Elem().Kind(); kind == reflect.Ptr || kind == reflect.Interface {
+			return marshalJSON(value)
+		}
+		delim := sliceDelimiter(tag)
+		if isKeyValue(value.Type().Elem()) {
+			entries := make([]string, value.Len())
+			for i := range entries {
+				pair := value.Index(i)
+				entry, err := formatEntry(pair.FieldByName("Key"), pair.FieldByName("Value"), tag, delim)
+				if err != nil {
+					return "", err
+				}
+				entries[i] = entry
+			}
+			return strings.Join(entries, delim), nil
+		}
+		if rows, ok := value.Interface().([][]string); ok && tag.Get("format") == "csv" {
+			return formatCSV(rows)
+		}
+		if value.Type().Elem().Kind() == reflect.Slice {
+			delim = tagDelimiter(tag, "rowdelim", ";")
+			tag = reflect.StructTag(fmt.Sprintf("delim:%q", tagDelimiter(tag, "coldelim", ",")))
+		}
+		elements := make([]string, value.Len())
+		for i := range elements {
+			element, err := formatEnvValue(value.Index(i), tag)
+			if err != nil {
+				return "", fmt.Errorf("element %d: %w", i, err)
+			}
+			elements[i] = element
+		}
+		return strings.Join(elements, delim), nil
+	case reflect.Map:
+		if !isScalar(value.Type().Elem()) {
+			return marshalJSON(value)
+		}
+		delim := sliceDelimiter(tag)
+		entries := make([]string, 0, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			entry, err := formatEntry(iter.Key(), iter.Value(), tag, delim)
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, entry)
+		}
+		// Map iteration order is random.
+		sort.Strings(entries)
+		return strings.Join(entries, delim), nil
+	}
+	return fmt.Sprint(value.Interface()), nil
+}
+
+// formatEntry formats a key=value pair of a map or OrderedMap, escaping the separators.
+func formatEntry(key, value reflect.Value, tag reflect.StructTag, delim string) (string, error) {
+	keyString, err := formatEnvValue(key, "")
+	if err != nil {
+		return "", err
+	}
+	valueString, err := formatEnvValue(value, tag)
+	if err != nil {
+		return "", fmt.Errorf("key %q: %w", keyString, err)
+	}
+	keyString = strings.ReplaceAll(escapeMapEntry(keyString, delim), "=", `\=`)
+	return keyString + "=" + escapeMapEntry(valueString, delim), nil
+}
+
+// marshalJSON renders a value as JSON.
+func marshalJSON(value reflect.Value) (string, error) {
+	data, err := json.Marshal(value.Interface())
+	return string(data), err
+}
+
+// escapeMapEntry escapes backslashes and the delimiter in a map key or value, see unescape.
+func escapeMapEntry(value, delim string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return strings.ReplaceAll(value, delim, `\`+delim)
+}
@@ -0,0 +1,25 @@
+package envflagparser
+
+import (
+	"reflect"
+	"time"
+)
+
+// MetricsSink receives metrics collected while parsing, e.g. to export them to Prometheus
+// without this package importing a metrics library.
+type MetricsSink interface {
+	// ObserveField is called for each field set, with its kind and the time spent parsing and validating its value.
+	ObserveField(kind reflect.Kind, dur time.Duration)
+	// ObserveError is called for each field whose value failed to parse or validate.
+	ObserveError(kind reflect.Kind, err error)
+}
+
+// Metrics defines the sink receiving parse metrics, discarding them by default.
+var Metrics MetricsSink = noopMetrics{}
+
+// noopMetrics is a MetricsSink discarding all metrics.
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveField(reflect.Kind, time.Duration) {}
+
+func (noopMetrics) ObserveError(reflect.Kind, error) {}
@@ -0,0 +1,49 @@
+package envflagparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseCents parses a decimal amount like 19.99 into integer cents like 1999 without float imprecision.
+// More than two decimal places are an error, unless round is set to round half away from zero.
+func parseCents(value string, round bool) (string, error) {
+	sign := ""
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		sign, value = strings.TrimPrefix(value[:1], "+"), value[1:]
+	}
+
+	units, fraction, _ := strings.Cut(value, ".")
+	if units == "" || strings.Trim(units+fraction, "0123456789") != "" {
+		return "", fmt.Errorf("invalid amount %s", sign+value)
+	}
+
+	roundUp := false
+	if len(fraction) > 2 {
+		if !round {
+			return "", fmt.Errorf("amount %s has more than two decimal places", sign+value)
+		}
+		roundUp = fraction[2] >= '5'
+		fraction = fraction[:2]
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	cents, err := strconv.ParseInt(units+fraction, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	if roundUp {
+		cents++
+	}
+	return sign + strconv.FormatInt(cents, 10), nil
+}
+
+// formatCents formats integer cents like 1999 as a decimal amount like 19.99.
+func formatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
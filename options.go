@@ -0,0 +1,48 @@
+package envflagparser
+
+import "io"
+
+// Options configures a single parse by ParseWith. Zero values fall back to the package-level settings.
+type Options struct {
+	// EnvFile is the path of the dotenv file read by the file stage, EnvFile if empty.
+	EnvFile string
+	// Defaults overrides default tags by field path like ParseConfigWithDefaultMap.
+	Defaults map[string]string
+	// PrioritiseFlags applies explicitly set flags after environment variables, as if PrioritiseEnv were false.
+	PrioritiseFlags bool
+	// EnvPrefix restricts the environment variables to those starting with the prefix like ParseConfigStripPrefix.
+	EnvPrefix string
+	// FlagValuePrefix is stripped from the values of string flags like Parser.FlagValuePrefix.
+	FlagValuePrefix string
+	// Output receives usage information on flag errors, regardless of PrintErrorUsage.
+	Output io.Writer
+	// StrictEnvFile rejects keys of the dotenv file not mapped to any field, as if StrictEnvFile were set.
+	StrictEnvFile bool
+	// Rounding defines the rounding of floats coerced to integer fields by LenientTypes, overriding FloatRounding
+	// unless it is RoundDefault. Rounding tags of fields take precedence.
+	Rounding RoundingMode
+}
+
+// ParseWith parses configuration values like ParseConfig with the provided options in one call,
+// e.g. ParseWith(config, Options{EnvFile: ".env", EnvPrefix: "SVC_", StrictEnvFile: true}).
+func ParseWith(configStruct interface{}, opts Options) error {
+	path := opts.EnvFile
+	if path == "" {
+		path = EnvFile
+	}
+	fileValues, err := readEnvFile(path)
+	if err != nil {
+		return err
+	}
+	_, err = registerAndParse(configStruct, parseOptions{
+		dotenv:          fileValues,
+		defaults:        opts.Defaults,
+		envPrefix:       opts.EnvPrefix,
+		flagValuePrefix: opts.FlagValuePrefix,
+		prioritiseFlags: opts.PrioritiseFlags,
+		output:          opts.Output,
+		strictEnvFile:   opts.StrictEnvFile,
+		rounding:        opts.Rounding,
+	})
+	return err
+}
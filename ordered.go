@@ -0,0 +1,32 @@
+package envflagparser
+
+// KV is a key-value pair of an OrderedMap.
+type KV[V any] struct {
+	Key   string
+	Value V
+}
+
+// OrderedMap is a map keeping the order of its entries, e.g. for ordered middleware.
+// It is parsed from key=value pairs like a=1,b=2 like maps. Slices of other structs
+// with just a Key and a Value field are parsed the same way.
+type OrderedMap[V any] []KV[V]
+
+// Get returns the value of the first entry with the key.
+func (m OrderedMap[V]) Get(key string) (V, bool) {
+	for _, kv := range m {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Keys returns the keys of the entries in their order.
+func (m OrderedMap[V]) Keys() []string {
+	keys := make([]string, len(m))
+	for i, kv := range m {
+		keys[i] = kv.Key
+	}
+	return keys
+}
@@ -6,13 +6,23 @@
 package envflagparser
 
 import (
+	"context"
+	"encoding"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
+	"math"
+	"net"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // PrioritiseEnv defines whether environment variables take precedence over flag values.
@@ -21,8 +31,143 @@ var PrioritiseEnv = true
 // PrintErrorUsage defines whether error messages should include usage information. (flags)
 var PrintErrorUsage = false
 
+// ExplicitFlagsOnly defines whether only flags set on the command line override other values.
+// If false, flag values including their defaults are applied to fields that are still zero.
+var ExplicitFlagsOnly = true
+
+// UnquoteFlagValues defines whether matching surrounding quotes are stripped from string flag values.
+var UnquoteFlagValues = false
+
+// AllowSharedEnv defines whether several fields may read the same environment variable.
+var AllowSharedEnv = false
+
+// UnmarshalTimeout defines how long the UnmarshalText method of a field type may take, e.g. if it performs I/O.
+// Zero disables the timeout.
+var UnmarshalTimeout time.Duration = 0
+
+// LenientTypes defines whether values are coerced across types for legacy tools: true and false set integer
+// fields to 1 and 0, integers set bool fields to true if nonzero, and floats set integer fields rounded by
+// FloatRounding, e.g. 3.7 to 3 by default. Fields tagged coerce:"int" still reject fractional values.
+// Otherwise types match strictly.
+var LenientTypes = false
+
+// StripListBrackets defines whether a pair of brackets around a delimited list like [a,b,c] is stripped
+// for slice fields. Otherwise the brackets are part of the first and last element, unless the value is a JSON array.
+var StripListBrackets = false
+
+// AllowRaggedRows defines whether rows of nested slice fields may have differing column counts.
+var AllowRaggedRows = true
+
+// InterspersedFlags defines whether flags after positional arguments are parsed too, like GNU tools do,
+// e.g. mytool file -verbose. Arguments after -- stay positional, ParseResult.Args holds the positional arguments.
+var InterspersedFlags = false
+
+// TagNames defines the names of the struct tags read by the parser.
+// Empty names fall back to the default tag names env, flag, default and usage.
+type TagNames struct {
+	Env     string
+	Flag    string
+	Default string
+	Usage   string
+}
+
+// Tags defines the struct tag names read by the parser, e.g. TagNames{Env: "config", Flag: "cli"}.
+var Tags = TagNames{}
+
 // ParseConfig parses configuration values from flags and environment variables into the provided struct.
-func ParseConfig(configStruct interface{}) (err error) {
+// The value of each field is resolved by the pipeline described at Stage.
+func ParseConfig(configStruct interface{}) error {
+	_, err := RegisterAndParse(configStruct)
+	return err
+}
+
+// ParseResult holds the flag values of a parse before they were merged with the other stages.
+type ParseResult struct {
+	// FlagValues holds the typed value of each registered flag by name, explicitly set or defaulted.
+	FlagValues map[string]interface{}
+	// SetFlags holds the names of the flags explicitly set on the command line.
+	SetFlags map[string]bool
+	// Sources holds the stage each field was resolved from by field path, zero if none provided a value.
+	Sources map[string]Stage
+	// Args holds the positional arguments remaining after the flags.
+	Args []string
+}
+
+// RegisterAndParse parses configuration values like ParseConfig and additionally returns the parsed flag values.
+func RegisterAndParse(configStruct interface{}) (*ParseResult, error) {
+	fileValues, err := readEnvFile(EnvFile)
+	if err != nil {
+		return nil, err
+	}
+	return registerAndParse(configStruct, parseOptions{dotenv: fileValues})
+}
+
+// ParseConfigWithDefaultMap parses configuration values like ParseConfig with the defaults of the map,
+// keyed by field name, taking the place of the default tags. Tag defaults are used for fields missing in the map.
+func ParseConfigWithDefaultMap(configStruct interface{}, defaults map[string]string) error {
+	return ParseWith(configStruct, Options{Defaults: defaults})
+}
+
+// ParseConfigWithDefaultFile parses configuration values like ParseConfig with the defaults of a key=value file,
+// keyed by environment variable name, taking the place of the default tags, e.g. to share defaults across services.
+// Tag defaults are used for fields missing in the file.
+func ParseConfigWithDefaultFile(configStruct interface{}, path string) error {
+	fileDefaults, err := readDotenvFile(path)
+	if err != nil {
+		return err
+	}
+
+	fields, err := collectFields(reflect.ValueOf(configStruct).Elem())
+	if err != nil {
+		return err
+	}
+	defaults := make(map[string]string)
+	for _, f := range fields {
+		if value, ok := fileDefaults[f.EnvKey]; ok && f.EnvKey != "" {
+			defaults[f.Path] = value
+		}
+	}
+
+	return ParseConfigWithDefaultMap(configStruct, defaults)
+}
+
+// ParseConfigStripPrefix parses configuration values like ParseConfig, considering only environment variables
+// starting with prefix. The prefix is stripped before matching the env tags, e.g. SVC_PORT sets the field
+// tagged env:"PORT" with the prefix SVC_, while PORT is ignored.
+func ParseConfigStripPrefix(configStruct interface{}, prefix string) error {
+	return ParseWith(configStruct, Options{EnvPrefix: prefix})
+}
+
+// parseOptions holds the inputs of a parse besides the package-level settings.
+type parseOptions struct {
+	// file is the source of the file stage, nil if none.
+	file fileSource
+	// dotenv holds the dotenv values of the file stage keyed by environment variable name, used if file is nil.
+	dotenv map[string]string
+	// defaults overrides default tags by field name.
+	defaults map[string]string
+	// envPrefix is the prefix of the environment variables considered, stripped before matching env tags.
+	envPrefix string
+	// flagValuePrefix is stripped from the values of string flags.
+	flagValuePrefix string
+	// prioritiseFlags applies flags after environment variables regardless of PrioritiseEnv.
+	prioritiseFlags bool
+	// output receives usage information on flag errors regardless of PrintErrorUsage, if set.
+	output io.Writer
+	// strictEnvFile rejects unmapped dotenv keys regardless of StrictEnvFile.
+	strictEnvFile bool
+	// rounding overrides FloatRounding if it is not RoundDefault.
+	rounding RoundingMode
+}
+
+// prioritiseEnv reports whether the env stage is applied after the flag stage.
+func (opts parseOptions) prioritiseEnv() bool {
+	return PrioritiseEnv && !opts.prioritiseFlags
+}
+
+// registerAndParse registers the flags of the provided struct, parses them and resolves
+// each field through the pipeline.
+func registerAndParse(configStruct interface{}, opts parseOptions) (result *ParseResult, err error) {
 	// flag.Parse() panics
 	defer func() {
 		if r := recover(); r != nil {
@@ -30,232 +175,1182 @@ func ParseConfig(configStruct interface{}) (err error) {
 		}
 	}()
 
-	// Panic instead of exit
-	flag.CommandLine.Init("envflagparser", flag.PanicOnError)
+	if defaulter, ok := configStruct.(Defaulter); ok {
+		defaulter.SetDefaults()
+	}
+
+	// Each parse registers its flags in a fresh flag set, which panics instead of exiting.
+	// Flags the application defined on flag.CommandLine are registered too, so their arguments are accepted.
+	fs := flag.NewFlagSet("envflagparser", flag.PanicOnError)
+	flag.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
 
 	// If PrintErrorUsage is false, discard usage information.
-	if !PrintErrorUsage {
-		flag.CommandLine.SetOutput(io.Discard)
+	if opts.output != nil {
+		fs.SetOutput(opts.output)
+	} else if !PrintErrorUsage {
+		fs.SetOutput(io.Discard)
+	}
+
+	fields, err := collectFields(reflect.ValueOf(configStruct).Elem())
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		fields[i].EnvPrefix = opts.envPrefix
+		fields[i].Rounding = opts.rounding
 	}
 
-	elem := reflect.ValueOf(configStruct).Elem()
-	typ := elem.Type()
+	if err := validateTags(fields); err != nil {
+		return nil, err
+	}
+
+	file := opts.file
+	if opts.dotenv != nil {
+		if err := checkEnvFileKeys(fields, opts.dotenv, StrictEnvFile || opts.strictEnvFile); err != nil {
+			return nil, err
+		}
+		file = envFileSource(opts.dotenv)
+	}
+
+	defaults, err := resolveDefaults(fields, opts.defaults)
+	if err != nil {
+		return nil, err
+	}
 
-	flagValues := make(map[string]interface{})
+	stages := enabledStages()
 
-	// Iterate over fields in the provided struct.
-	for i := 0; i < elem.NumField(); i++ {
-		field := elem.Field(i)
-		fieldType := typ.Field(i)
+	// Flags registered for fields by field index.
+	flagFields := make(map[int]*fieldFlag)
 
-		// Get flag and environment variable names, default value, and usage information.
-		envKey := fieldType.Tag.Get("env")
-		flagName := fieldType.Tag.Get("flag")
-		defaultValue := fieldType.Tag.Get("default")
-		usage := fieldType.Tag.Get("usage")
+	// Environment variables read by a field, and catch-all fields collecting the rest.
+	claimedEnv := make(map[string]bool)
+	var catchAllFields []int
 
-		// Check if environment variable exists and set the field accordingly.
-		envValue, envExists := os.LookupEnv(envKey)
-		if envExists {
-			setValue(field, envValue)
+	// Register a flag for each field with the default value.
+	for i, f := range fields {
+		// Catch-all fields are filled after all other fields claimed their variables.
+		if f.Tag.Get("catchall") == "true" {
+			catchAllFields = append(catchAllFields, i)
+			continue
+		}
+		if isIndexedStruct(f.Tag) {
+			continue
 		}
 
-		// Get flag value based on field type.
-		if flagName != "" {
-			flagSetValue, err := getFlagSetValue(field, flagName, defaultValue, usage)
-			if err != nil {
-				return err
+		claimEnv(claimedEnv, f)
+
+		if flagName := flagTag(f.Tag); flagName != "" && stages&StageFlag != 0 {
+			checkDefaultMismatch(f, flagName, defaults[i])
+			if fs.Lookup(flagName) != nil {
+				return nil, fmt.Errorf("flag redefined: %s", flagName)
+			}
+			if err := checkFlagType(f); err != nil {
+				return nil, err
 			}
 
-			flagValues[flagName] = flagSetValue
+			flagFields[i] = newFieldFlag(f, defaults[i])
+			flagFields[i].valuePrefix = opts.flagValuePrefix
+			fs.Var(flagFields[i], flagName, usageTag(f.Tag))
+		}
+	}
 
-			println(len(flagValues))
-			println(flagName)
-		} else if !envExists && defaultValue != "" {
-			setValue(field, defaultValue)
+	// Collect unclaimed environment variables into catch-all fields.
+	for _, i := range catchAllFields {
+		if err := setCatchAll(fields[i], claimedEnv); err != nil {
+			return nil, err
 		}
 	}
 
-	// Parse command-line flags.
-	flag.Parse()
+	// Flags explicitly set on the command line.
+	setFlags := make(map[string]bool)
+	args := os.Args[1:]
+	if stages&StageFlag != 0 {
+		hideFlags(fs)
+
+		// Parse command-line flags.
+		if InterspersedFlags {
+			args = reorderArgs(fs, args)
+		}
+		if err := parseFlags(fs, args, fields, flagFields); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+
+		fs.Visit(func(f *flag.Flag) {
+			setFlags[f.Name] = true
+		})
+	}
+
+	result = &ParseResult{FlagValues: make(map[string]interface{}), SetFlags: setFlags, Sources: make(map[string]Stage), Args: args}
+	for i, flagValue := range flagFields {
+		flagName := flagTag(fields[i].Tag)
+		// Unset flags without a default and flags set to the UnsetSentinel keep the zero value.
+		typedValue := reflect.New(flagValue.typ).Elem()
+		if (!setFlags[flagName] && flagValue.value == "") || isUnsetSentinel(flagValue.fieldValue()) {
+			result.FlagValues[flagName] = typedValue.Interface()
+			continue
+		}
+		if err := flagValue.parse(typedValue, flagValue.fieldValue()); err != nil {
+			return nil, fieldError(fields[i], fmt.Errorf("invalid value %q for field %s: %w", flagValue.fieldValue(), fields[i].Path, err))
+		}
+		result.FlagValues[flagName] = typedValue.Interface()
+	}
 
-	// Set field values based on flag values.
-	for flagName, flagValue := range flagValues {
-		fieldIndex := getFieldIndexByFlagName(typ, flagName)
-		if fieldIndex != -1 {
-			field := elem.Field(fieldIndex)
-			// Check if the field is already set
-			// Also if PrioritiseEnv is false, overwrite it
-			if !PrioritiseEnv || field.IsZero() {
-				if err := setFieldValueByFlagValue(field, flagValue); err != nil {
-					return err
+	// Resolve each field through the pipeline.
+	for i, f := range fields {
+		if f.Tag.Get("catchall") == "true" {
+			continue
+		}
+		// Indexed struct fields are set from their groups of environment variables by the env stage.
+		if isIndexedStruct(f.Tag) {
+			if stages&StageEnv != 0 {
+				if err := setIndexedStructs(f); err != nil {
+					return nil, err
 				}
+				result.Sources[f.Path] = StageEnv
+			}
+			continue
+		}
+
+		values, err := lookupStageValues(f, defaults[i], file)
+		if err != nil {
+			return nil, err
+		}
+		flagValue, hasFlag := flagFields[i]
+		if hasFlag && setFlags[flagTag(f.Tag)] {
+			values[StageFlag] = flagValue.fieldValue()
+		}
+
+		value, stage := values.resolve(f.Tag, opts.prioritiseEnv())
+		if isRequired(f.Tag) && (stage == 0 || stage == StageDefault) {
+			return nil, requiredError(f)
+		}
+		if stage != 0 {
+			if err := setFieldValue(f, value); err != nil {
+				return nil, err
+			}
+		}
+
+		// Legacy merge: flag values including their defaults are applied to fields not set by an environment
+		// variable. Explicitly set flags override environment variables too if PrioritiseEnv is false.
+		// Checking the stage instead of a zero value keeps an explicit false or 0 from the environment.
+		overridesEnv := !opts.prioritiseEnv() && setFlags[flagTag(f.Tag)]
+		if !ExplicitFlagsOnly && hasFlag && (stage != StageEnv || overridesEnv) && !isUnsetSentinel(flagValue.fieldValue()) {
+			value = flagValue.fieldValue()
+			if err := setFieldValue(f, value); err != nil {
+				return nil, err
+			}
+			stage = StageDefault
+			if setFlags[flagTag(f.Tag)] {
+				stage = StageFlag
+			}
+		}
+
+		// The fallback tag is the last resort for fields left without a value, after flag defaults too.
+		if fallback, ok := lookupFallback(f.Tag, value, stage); ok {
+			if err := setFieldValue(f, fallback); err != nil {
+				return nil, err
 			}
+			stage = StageDefault
 		}
+		result.Sources[f.Path] = stage
 	}
 
+	if err := runValidators(configStruct); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validateTags checks the struct tags of all fields for contradictions.
+func validateTags(fields []configField) error {
+	envFields := make(map[string]string)
+	for _, f := range fields {
+		if isUnparsableKind(f.Type.Kind()) {
+			return fmt.Errorf("field %s has an env or flag tag, but fields of kind %s cannot be parsed", f.Path, f.Type.Kind())
+		}
+
+		if errcode, ok := f.Tag.Lookup("errcode"); ok {
+			if _, ok := errorCode(f.Tag); !ok {
+				return fmt.Errorf("field %s has an invalid errcode tag %q, expected an integer", f.Path, errcode)
+			}
+		}
+
+		// A default value makes a field optional.
+		if isRequired(f.Tag) && defaultTag(f.Tag) != "" {
+			return fmt.Errorf("field %s is required but has a default value", f.Path)
+		}
+
+		// Fields reading the same environment variable are usually a copy-paste mistake.
+		if f.EnvKey == "" || f.Tag.Get("catchall") == "true" || AllowSharedEnv {
+			continue
+		}
+		key := f.EnvKey
+		if CaseInsensitiveEnv {
+			key = strings.ToUpper(key)
+		}
+		if other, ok := envFields[key]; ok {
+			return fmt.Errorf("environment variable %s is read by fields %s and %s", f.EnvKey, other, f.Path)
+		}
+		envFields[key] = f.Path
+	}
 	return nil
 }
 
-// getFieldIndexByFlagName retrieves the index of a field by its flag name.
-func getFieldIndexByFlagName(typ reflect.Type, flagName string) int {
-	for i := 0; i < typ.NumField(); i++ {
-		fieldType := typ.Field(i)
-		if fieldType.Tag.Get("flag") != "" && fieldType.Tag.Get("flag") == flagName {
+// isRequired reports whether a field is tagged as required.
+func isRequired(tag reflect.StructTag) bool {
+	return tag.Get("required") == "true"
+}
+
+// requiredError returns the ParseError of a required field that was not provided.
+func requiredError(f configField) error {
+	var sources []string
+	if f.EnvKey != "" {
+		sources = append(sources, "environment variable "+f.EnvKey)
+	}
+	if flagName := flagTag(f.Tag); flagName != "" {
+		sources = append(sources, "flag -"+flagName)
+	}
+	if len(sources) == 0 {
+		return fieldError(f, fmt.Errorf("required field %s is not set", f.Path))
+	}
+	return fieldError(f, fmt.Errorf("required field %s is not set, provide %s", f.Path, strings.Join(sources, " or ")))
+}
+
+// parseFlags parses args with a flag set panicking on errors.
+// Errors of flag values failing to parse for their field are returned as its ParseError.
+func parseFlags(fs *flag.FlagSet, args []string, fields []configField, flagFields map[int]*fieldFlag) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		var ok bool
+		if err, ok = r.(error); !ok {
+			err = fmt.Errorf("%v", r)
+		}
+		for i, flagField := range flagFields {
+			if flagField.err != nil {
+				err = fieldError(fields[i], err)
+			}
+		}
+	}()
+	fs.Parse(args)
+	return nil
+}
+
+// setFieldValue sets and validates the value of a field, reporting it to the Metrics sink.
+// Errors are returned as ParseError.
+func setFieldValue(f configField, value string) error {
+	start := time.Now()
+	err := setAndValidate(f, value)
+	if err != nil {
+		Metrics.ObserveError(f.Type.Kind(), err)
+		return fieldError(f, err)
+	}
+	Metrics.ObserveField(f.Type.Kind(), time.Since(start))
+	return nil
+}
+
+// setAndValidate resolves secrets, transforms, sets and validates the value of a field, naming the field in errors.
+func setAndValidate(f configField, value string) error {
+	resolved, err := resolveSecret(f.Tag, value)
+	if err != nil {
+		return fmt.Errorf("invalid value %q for field %s: %w", value, f.Path, err)
+	}
+	transformed, err := transformValue(f.Tag, resolved)
+	if err != nil {
+		return fmt.Errorf("invalid value %q for field %s: %w", value, f.Path, err)
+	}
+	// Errors show the reference of a resolved secret instead of the secret.
+	shown := transformed
+	if isSecretRef(f.Tag, value) {
+		shown = value
+	}
+	value = transformed
+	if setter := lookupFieldSetter(f); setter != nil {
+		err = setter(f.Value, value)
+	} else {
+		err = setRoundedValue(f.Value, f.Tag, value, f.Rounding)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q for field %s: %w", shown, f.Path, err)
+	}
+	if err := checkMaxItems(f.Value, f.Tag); err != nil {
+		return fmt.Errorf("field %s %w", f.Path, err)
+	}
+	if err := validateValue(f.Value, f.Tag); err != nil {
+		return fmt.Errorf("field %s %w", f.Path, err)
+	}
+	return nil
+}
+
+// lookupTag returns the value of the struct tag name, or of defaultName if name is empty.
+func lookupTag(tag reflect.StructTag, name, defaultName string) string {
+	if name == "" {
+		name = defaultName
+	}
+	return tag.Get(name)
+}
+
+// envTag returns the environment variable name of a field.
+func envTag(tag reflect.StructTag) string {
+	return lookupProfileTag(tag, Tags.Env, "env")
+}
+
+// flagTag returns the flag name of a field.
+func flagTag(tag reflect.StructTag) string {
+	return lookupTag(tag, Tags.Flag, "flag")
+}
+
+// defaultTag returns the default value of a field, preferring the default of the active environment.
+func defaultTag(tag reflect.StructTag) string {
+	if value, ok := lookupEnvironmentDefault(tag); ok {
+		return value
+	}
+	return lookupProfileTag(tag, Tags.Default, "default")
+}
+
+// usageTag returns the usage information of a field.
+func usageTag(tag reflect.StructTag) string {
+	return lookupProfileTag(tag, Tags.Usage, "usage")
+}
+
+// defaultRefPattern matches ${name} references to sibling fields in default values.
+var defaultRefPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// resolveDefaults returns the default value of each field with ${name} references replaced
+// by the resolved value of the referenced sibling field, i.e. its environment variable or default.
+// Siblings are referenced by their flag name, environment variable name, or field name.
+// Overrides replace the default tags by field name, or dotted path for fields of nested structs.
+func resolveDefaults(fields []configField, overrides map[string]string) ([]string, error) {
+	const (
+		unresolved = iota
+		resolving
+		resolved
+	)
+
+	defaults := make([]string, len(fields))
+	states := make([]int, len(fields))
+
+	var resolve func(i int) error
+	resolve = func(i int) error {
+		switch states[i] {
+		case resolving:
+			return fmt.Errorf("cyclic default reference in field %s", fields[i].Path)
+		case resolved:
+			return nil
+		}
+		states[i] = resolving
+
+		defaultValue, ok := overrides[fields[i].Path]
+		if !ok {
+			defaultValue = defaultTag(fields[i].Tag)
+		}
+
+		var err error
+		defaults[i] = defaultRefPattern.ReplaceAllStringFunc(defaultValue, func(ref string) string {
+			if err != nil {
+				return ""
+			}
+			j := getFieldIndexByReference(fields, defaultRefPattern.FindStringSubmatch(ref)[1])
+			if j == -1 {
+				err = fmt.Errorf("unknown reference %s in default of field %s", ref, fields[i].Path)
+				return ""
+			}
+			if fields[j].EnvKey != "" {
+				if envValue, ok := lookupEnv(fields[j].EnvPrefix + fields[j].EnvKey); ok {
+					return envValue
+				}
+			}
+			if err = resolve(j); err != nil {
+				return ""
+			}
+			return defaults[j]
+		})
+
+		states[i] = resolved
+		return err
+	}
+
+	for i := range defaults {
+		if err := resolve(i); err != nil {
+			return nil, err
+		}
+	}
+	return defaults, nil
+}
+
+// getFieldIndexByReference retrieves the index of a field by its flag name, environment variable name, field name or path.
+func getFieldIndexByReference(fields []configField, name string) int {
+	for i, f := range fields {
+		if flagTag(f.Tag) == name || f.EnvKey == name || f.Name == name || f.Path == name {
 			return i
 		}
 	}
 	return -1
 }
 
-// unclean code :(
-// TODO: A map with the conversion function
+// setCatchAll assigns all environment variables starting with the field's env prefix
+// that are not claimed by another field to a map[string]string catch-all field.
+// The EnvPrefix of the parse is stripped from the keys.
+func setCatchAll(f configField, claimedEnv map[string]bool) error {
+	if f.Type != reflect.TypeOf(map[string]string(nil)) {
+		return fmt.Errorf("catch-all field %s must be of type map[string]string", f.Path)
+	}
+
+	prefix := f.EnvPrefix + f.EnvKey
+	if CaseInsensitiveEnv {
+		prefix = strings.ToUpper(prefix)
+		folded := make(map[string]bool, len(claimedEnv))
+		for key := range claimedEnv {
+			folded[strings.ToUpper(key)] = true
+		}
+		claimedEnv = folded
+	}
+
+	unclaimed := make(map[string]string)
+	for _, entry := range environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		name := key
+		if CaseInsensitiveEnv {
+			name = strings.ToUpper(key)
+		}
+		if strings.HasPrefix(name, prefix) && !claimedEnv[name] {
+			unclaimed[key[len(f.EnvPrefix):]] = value
+		}
+	}
+	f.Value.Set(reflect.ValueOf(unclaimed))
+	return nil
+}
+
+// StringSetter is implemented by field types parsing their values themselves, as a lighter-weight
+// alternative to flag.Value. The method is called on a pointer to the field.
+type StringSetter interface {
+	SetFromString(value string) error
+}
+
+// Defaulter is implemented by config types seeding their defaults in code, e.g. values richer than default tags
+// can express. SetDefaults is called on the config before each parse, default tags, the dotenv file, environment
+// variables and flags then override the values it set.
+type Defaulter interface {
+	SetDefaults()
+}
 
 // setValue sets the value of a field based on its type.
-func setValue(field reflect.Value, value string) error {
+func setValue(field reflect.Value, tag reflect.StructTag, value string) error {
+	return setRoundedValue(field, tag, value, RoundDefault)
+}
+
+// setRoundedValue sets the value of a field like setValue, rounding floats coerced to integers by LenientTypes
+// with the rounding mode if the field has no rounding tag.
+func setRoundedValue(field reflect.Value, tag reflect.StructTag, value string, rounding RoundingMode) error {
+	// Types with a parser registered by RegisterParser are parsed by it.
+	if parse := lookupTypeParser(field.Type()); parse != nil {
+		parsed, err := parse(value)
+		if err != nil {
+			return err
+		}
+		field.Set(parsed)
+		return nil
+	}
+
+	// time.Time fields accept signed offsets from now like +2h or -30m besides RFC 3339 timestamps.
+	if field.Type() == reflect.TypeOf(time.Time{}) && (strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-")) {
+		offset, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(time.Now().Add(offset)))
+		return nil
+	}
+
+	// Fields tagged format:"yaml" are set from a block of indented key: value lines.
+	if tag.Get("format") == "yaml" {
+		return setYAML(field, value)
+	}
+
+	// Fields tagged format:"csv" are set from newline-separated CSV rows.
+	if tag.Get("format") == "csv" {
+		return setCSV(field, value)
+	}
+
+	// Fields tagged format:"base64json" are decoded from base64 and unmarshalled from JSON.
+	if tag.Get("format") == "base64json" {
+		return setBase64JSON(field, value)
+	}
+
+	// Input fields tagged stdinaware read stdin for the path -.
+	if tag.Get("stdinaware") == "true" && setInput(field, value) {
+		return nil
+	}
+
+	// MAC addresses are byte slices, parsed from their colon-, hyphen- or dot-separated notation.
+	if field.Type() == reflect.TypeOf(net.HardwareAddr(nil)) {
+		mac, err := net.ParseMAC(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(mac))
+		return nil
+	}
+
+	// Patterns of *regexp.Regexp fields are compiled once while parsing.
+	if field.Type() == reflect.TypeOf((*regexp.Regexp)(nil)) {
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", value, err)
+		}
+		field.Set(reflect.ValueOf(pattern))
+		return nil
+	}
+
+	// Time zones are loaded by their IANA name like America/New_York.
+	if field.Type() == reflect.TypeOf(time.Location{}) || field.Type() == reflect.TypeOf((*time.Location)(nil)) {
+		location, err := time.LoadLocation(value)
+		if err != nil {
+			return fmt.Errorf("invalid time zone %q: %w", value, err)
+		}
+		if field.Kind() == reflect.Ptr {
+			field.Set(reflect.ValueOf(location))
+		} else {
+			field.Set(reflect.ValueOf(location).Elem())
+		}
+		return nil
+	}
+
+	// Types with a SetFromString method or implementing encoding.TextUnmarshaler like net.IP parse themselves,
+	// atomic wrappers like atomic.Int64 store the parsed value they hold.
+	if field.CanAddr() {
+		if isAtomic(field.Type()) {
+			return storeAtomic(field, tag, value, rounding)
+		}
+		switch setter := field.Addr().Interface().(type) {
+		case StringSetter:
+			return setter.SetFromString(value)
+		case encoding.TextUnmarshaler:
+			err := unmarshalText(field, value)
+			if err != nil && field.Type() == reflect.TypeOf(slog.Level(0)) {
+				return fmt.Errorf("%w, accepted levels are DEBUG, INFO, WARN and ERROR with an optional offset like INFO+2", err)
+			}
+			return err
+		}
+	}
+
 	switch field.Kind() {
-	case reflect.Int, reflect.Int64:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		if field.Type() == reflect.TypeOf(time.Duration(0)) {
 			// Convert string to duration and set field value.
-			durationValue, err := time.ParseDuration(value)
+			durationValue, err := parseDuration(value, tag.Get("durationunit"))
 			if err != nil {
 				return err
 			}
-			field.Set(reflect.ValueOf(durationValue))
-		} else {
-			// Convert string to int64 and set field value.
-			intValue, err := strconv.ParseInt(value, 10, 64)
+			field.SetInt(int64(durationValue))
+			break
+		}
+		if field.Kind() == reflect.Int32 {
+			value = charCodePoint(tag, value)
+		}
+		// Amounts of fields tagged money are converted to cents.
+		if money := tag.Get("money"); money != "" {
+			cents, err := parseCents(value, money == "round")
 			if err != nil {
 				return err
 			}
-			field.SetInt(intValue)
+			value = cents
 		}
-
-	case reflect.Uint:
-		// Convert string to uint64 and set field value.
-		uintValue, err := strconv.ParseUint(value, 10, 64)
+		value, err := integerValue(tag, value, rounding)
 		if err != nil {
 			return err
 		}
-		field.SetUint(uintValue)
-	case reflect.Uint64:
-		// Convert string to uint64 and set field value.
-		uint64Value, err := strconv.ParseUint(value, 10, 64)
+		// Convert string to an integer of the field's size and set field value, keeping named types like Port.
+		intValue, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(intValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if field.Type() == reflect.TypeOf(os.FileMode(0)) {
+			// Convert octal permissions like 0644 or 644 to a file mode.
+			mode, err := strconv.ParseUint(strings.TrimPrefix(value, "0o"), 8, 32)
+			if err != nil {
+				return fmt.Errorf("invalid octal file mode %q", value)
+			}
+			field.SetUint(mode)
+			break
+		}
+		if field.Kind() == reflect.Uint8 {
+			value = charCodePoint(tag, value)
+		}
+		// Negative values are rejected before LenientTypes could round fractions like -0.4 to 0.
+		if strings.HasPrefix(value, "-") {
+			return fmt.Errorf("negative value %s for unsigned integer field", value)
+		}
+		value, err := integerValue(tag, value, rounding)
+		if err != nil {
+			return err
+		}
+		// Convert string to an unsigned integer of the field's size and set field value.
+		uintValue, err := strconv.ParseUint(value, 10, field.Type().Bits())
 		if err != nil {
 			return err
 		}
-		field.SetUint(uint64Value)
-	case reflect.Float64:
-		// Convert string to float64 and set field value.
-		floatValue, err := strconv.ParseFloat(value, 64)
+		field.SetUint(uintValue)
+	case reflect.Float32, reflect.Float64:
+		// Convert string to a float of the field's size and set field value.
+		floatValue, err := strconv.ParseFloat(normalizeDecimal(tag, value), field.Type().Bits())
 		if err != nil {
 			return err
 		}
 		field.SetFloat(floatValue)
 	case reflect.String:
+		// Paths of fields tagged expandhome starting with ~ are relative to the home directory.
+		if tag.Get("expandhome") == "true" {
+			var err error
+			if value, err = expandHome(value); err != nil {
+				return err
+			}
+		}
 		// Set string field value.
 		field.SetString(value)
 	case reflect.Bool:
-		// Convert string to bool and set field value.
-		boolValue, err := strconv.ParseBool(value)
+		// Convert string to bool, accepting the vocabulary of the truevals and falsevals tags, and set field value.
+		boolValue, err := parseBool(tag, value)
+		if err != nil && LenientTypes {
+			if intValue, intErr := strconv.ParseInt(value, 10, 64); intErr == nil {
+				boolValue, err = intValue != 0, nil
+			}
+		}
 		if err != nil {
 			return err
 		}
 		field.SetBool(boolValue)
+	case reflect.Map:
+		return setMap(field, tag, value, rounding)
+	case reflect.Ptr:
+		// Allocate the pointer and set the value it points to.
+		ptr := reflect.New(field.Type().Elem())
+		if err := setRoundedValue(ptr.Elem(), tag, value, rounding); err != nil {
+			return err
+		}
+		field.Set(ptr)
+	case reflect.Slice:
+		// Nested slices are parsed as rows and columns.
+		if field.Type().Elem().Kind() == reflect.Slice {
+			return setMatrix(field, tag, value)
+		}
+		// Slices of key-value structs like OrderedMap are parsed from key=value pairs.
+		if isKeyValue(field.Type().Elem()) && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+			return setOrderedMap(field, tag, value, rounding)
+		}
+		// JSON arrays are decoded as a whole, null elements of pointer slices stay nil. Elements of []interface{}
+		// hold the JSON types: float64 numbers, strings, bools, nil, []interface{} and map[string]interface{}.
+		if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "[") && json.Valid([]byte(trimmed)) {
+			ptr := reflect.New(field.Type())
+			if err := json.Unmarshal([]byte(trimmed), ptr.Interface()); err != nil {
+				return err
+			}
+			field.Set(ptr.Elem())
+			return nil
+		}
+		// Slices of interface{} only hold the mixed values of JSON arrays, as delimited elements have no type.
+		if elemType := field.Type().Elem(); elemType.Kind() == reflect.Interface && elemType.NumMethod() == 0 {
+			return fmt.Errorf("%s fields are parsed from a JSON array like [1, \"a\", true]", field.Type())
+		}
+		// Lists wrapped in brackets which are not JSON arrays, like [a,b,c], are unwrapped if StripListBrackets is set.
+		if trimmed := strings.TrimSpace(value); StripListBrackets && len(trimmed) >= 2 && trimmed[0] == '[' && trimmed[len(trimmed)-1] == ']' {
+			value = trimmed[1 : len(trimmed)-1]
+		}
+		// Split string by the delimiter and set each element.
+		elements := splitElements(value, sliceDelimiter(tag))
+		if tag.Get("ranges") == "true" {
+			var err error
+			if elements, err = expandRanges(elements); err != nil {
+				return err
+			}
+		}
+		trim := isNumeric(field.Type().Elem())
+		slice := reflect.MakeSlice(field.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			// Numbers may be separated by a delimiter and spaces, e.g. 80, 443.
+			if trim {
+				element = strings.TrimSpace(element)
+			}
+			if err := setRoundedValue(slice.Index(i), tag, element, rounding); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
 	}
 	return nil
 }
 
-// getFlagSetValue gets the flag value corresponding to the field type.
-func getFlagSetValue(field reflect.Value, flagName, defaultValue, usage string) (interface{}, error) {
-	switch field.Kind() {
-	case reflect.Int:
-		// Convert default value to int and create an Int flag.
-		defaultIntValue, err := strconv.Atoi(defaultValue)
+// expandRanges expands inclusive integer ranges like 8000-8002 among the elements of a slice field tagged ranges,
+// keeping other elements like 9000 as they are.
+func expandRanges(elements []string) ([]string, error) {
+	var expanded []string
+	for _, element := range elements {
+		element = strings.TrimSpace(element)
+		i := strings.Index(element[min(1, len(element)):], "-") + 1
+		if i == 0 {
+			expanded = append(expanded, element)
+			continue
+		}
+		from, err := strconv.ParseInt(strings.TrimSpace(element[:i]), 10, 64)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid range %s: %w", element, err)
 		}
-		return flag.Int(flagName, defaultIntValue, usage), nil
-	case reflect.String:
-		// Create a String flag with default value.
-		return flag.String(flagName, defaultValue, usage), nil
-	case reflect.Bool:
-		// Convert default value to bool and create a Bool flag.
-		defaultBoolValue, err := strconv.ParseBool(defaultValue)
+		to, err := strconv.ParseInt(strings.TrimSpace(element[i+1:]), 10, 64)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid range %s: %w", element, err)
 		}
-		return flag.Bool(flagName, defaultBoolValue, usage), nil
-	case reflect.Int64:
-		if field.Type() == reflect.TypeOf(time.Duration(0)) {
-			// Parse default duration value and create a Duration flag.
-			defaultDurationValue, err := time.ParseDuration(defaultValue)
-			if err != nil {
-				return nil, err
-			}
-			return flag.Duration(flagName, defaultDurationValue, usage), nil
-		} else {
-			// Convert default value to int64 and create an Int64 flag.
-			defaultInt64Value, err := strconv.ParseInt(defaultValue, 10, 64)
-			if err != nil {
-				return nil, err
-			}
-			return flag.Int64(flagName, defaultInt64Value, usage), nil
+		if from > to {
+			return nil, fmt.Errorf("invalid range %s: descending bounds", element)
 		}
-	case reflect.Uint:
-		// Convert default value to uint64 and create a Uint flag.
-		defaultUintValue, err := strconv.ParseUint(defaultValue, 10, 64)
-		if err != nil {
-			return nil, err
+		for n := from; n <= to; n++ {
+			expanded = append(expanded, strconv.FormatInt(n, 10))
 		}
-		return flag.Uint(flagName, uint(defaultUintValue), usage), nil
-	case reflect.Uint64:
-		// Convert default value to uint64 and create a Uint64 flag.
-		defaultUint64Value, err := strconv.ParseUint(defaultValue, 10, 64)
+	}
+	return expanded, nil
+}
+
+// unmarshalText sets a field implementing encoding.TextUnmarshaler, bounded by UnmarshalTimeout.
+// The value is unmarshaled into a copy, so a timed out call cannot modify the field later.
+func unmarshalText(field reflect.Value, value string) error {
+	if UnmarshalTimeout <= 0 {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), UnmarshalTimeout)
+	defer cancel()
+
+	ptr := reflect.New(field.Type())
+	done := make(chan error, 1)
+	go func() {
+		done <- ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}()
+
+	select {
+	case err := <-done:
 		if err != nil {
-			return nil, err
+			return err
+		}
+		field.Set(ptr.Elem())
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("unmarshaling timed out after %s: %w", UnmarshalTimeout, ctx.Err())
+	}
+}
+
+// integerValue prepares the value of an integer field, combining the names of the bitflags tag, converting
+// the sizes of the bytesize tag or applying LenientTypes and the coerce tag, and rejects float-looking values remaining.
+func integerValue(tag reflect.StructTag, value string, rounding RoundingMode) (string, error) {
+	if _, ok := tag.Lookup("bitflags"); ok {
+		return parseBitFlags(tag, value)
+	}
+	if tag.Get("bytesize") == "true" {
+		return parseByteSize(value)
+	}
+	value, err := lenientInteger(tag, value, rounding)
+	if err != nil {
+		return "", err
+	}
+	if tag.Get("coerce") == "int" {
+		if value, err = coerceInteger(value); err != nil {
+			return "", err
 		}
-		return flag.Uint64(flagName, defaultUint64Value, usage), nil
-	case reflect.Float64:
-		// Convert default value to float64 and create a Float64 flag.
-		defaultFloatValue, err := strconv.ParseFloat(defaultValue, 64)
+	}
+	return value, checkInteger(value)
+}
+
+// checkInteger rejects float values like 3.0 or 1e2 for integer fields, which must not be truncated.
+// Other invalid values like eight are left to the integer parser.
+func checkInteger(value string) error {
+	if strings.ContainsAny(value, ".eE") {
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil
+		}
+		return fmt.Errorf("float value %s for integer field", value)
+	}
+	return nil
+}
+
+// coerceInteger converts a whole number in decimal or exponent notation like 1e9 to its integer form
+// for integer fields tagged coerce:"int", rejecting fractional values like 1.5e0.
+func coerceInteger(value string) (string, error) {
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return "", err
+	}
+	if floatValue != math.Trunc(floatValue) || math.IsInf(floatValue, 0) {
+		return "", fmt.Errorf("value %s is not a whole number", value)
+	}
+	return strconv.FormatFloat(floatValue, 'f', 0, 64), nil
+}
+
+// expandHome replaces a leading ~ of a path like ~ or ~/data with the home directory of the user.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// charCodePoint converts the single character value of a rune or byte field tagged char to its code point.
+func charCodePoint(tag reflect.StructTag, value string) string {
+	if tag.Get("char") == "true" && utf8.RuneCountInString(value) == 1 {
+		r, _ := utf8.DecodeRuneInString(value)
+		return strconv.Itoa(int(r))
+	}
+	return value
+}
+
+// normalizeDecimal converts a float of a locale format to the format of strconv.ParseFloat, stripping the
+// thousandsep tag and replacing the decimalsep tag by a point, e.g. 1.234,5 with thousandsep:"." and decimalsep:",".
+func normalizeDecimal(tag reflect.StructTag, value string) string {
+	if sep := tag.Get("thousandsep"); sep != "" {
+		value = strings.ReplaceAll(value, sep, "")
+	}
+	if sep := tag.Get("decimalsep"); sep != "" {
+		value = strings.Replace(value, sep, ".", 1)
+	}
+	return value
+}
+
+// parseBool parses a bool from the comma-separated words of the truevals and falsevals tags, e.g. yes and no,
+// compared case-insensitively, or from the values accepted by strconv.ParseBool.
+func parseBool(tag reflect.StructTag, value string) (bool, error) {
+	for _, word := range boolWords(tag, "truevals") {
+		if strings.EqualFold(value, word) {
+			return true, nil
+		}
+	}
+	for _, word := range boolWords(tag, "falsevals") {
+		if strings.EqualFold(value, word) {
+			return false, nil
+		}
+	}
+	return strconv.ParseBool(value)
+}
+
+// lenientInteger converts bool values to 1 or 0 and rounds floats for integer fields if LenientTypes is set.
+// Floats are left to the coerce tag for fields tagged coerce:"int".
+func lenientInteger(tag reflect.StructTag, value string, rounding RoundingMode) (string, error) {
+	if !LenientTypes {
+		return value, nil
+	}
+	if boolValue, err := strconv.ParseBool(value); err == nil {
+		if boolValue {
+			return "1", nil
+		}
+		return "0", nil
+	}
+	if tag.Get("coerce") == "int" {
+		return value, nil
+	}
+	if floatValue, err := strconv.ParseFloat(value, 64); err == nil && checkInteger(value) != nil {
+		mode, err := roundingMode(tag, rounding)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
-		return flag.Float64(flagName, defaultFloatValue, usage), nil
-	}
-	return nil, nil
-}
-
-// setFieldValueByFlagValue sets the value of a field based on the provided flag value.
-func setFieldValueByFlagValue(field reflect.Value, flagValue interface{}) error {
-	switch fv := flagValue.(type) {
-	case *int:
-		// Set field value with int.
-		setValue(field, strconv.Itoa(*fv))
-	case *string:
-		// Set field value with string.
-		setValue(field, *fv)
-	case *bool:
-		// Set field value with bool.
-		setValue(field, strconv.FormatBool(*fv))
-	case *int64:
-		// Set field value with int64.
-		setValue(field, strconv.FormatInt(*fv, 10))
-	case *uint:
-		// Set field value with uint.
-		setValue(field, strconv.FormatUint(uint64(*fv), 10))
-	case *uint64:
-		// Set field value with uint64.
-		setValue(field, strconv.FormatUint(*fv, 10))
-	case *float64:
-		// Set field value with float64.
-		setValue(field, strconv.FormatFloat(*fv, 'f', -1, 64))
-	case *time.Duration:
-		// Set field value with duration string.
-		setValue(field, (*fv).String())
-	default:
-		return fmt.Errorf("unsupported flag value type: %T", flagValue)
+		return strconv.FormatFloat(mode.round(floatValue), 'f', 0, 64), nil
+	}
+	return value, nil
+}
+
+// boolWords returns the comma-separated words of the truevals or falsevals tag.
+func boolWords(tag reflect.StructTag, name string) []string {
+	var words []string
+	for _, word := range strings.Split(tag.Get(name), ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// setMap sets a map field. Maps of scalar values are parsed from key=value pairs separated by the
+// delim tag (, by default), maps of other values like structs are decoded from a JSON object.
+// Maps of scalar slices also accept pairs, repeated keys appending to their slice, e.g. a=1,a=2,b=3.
+func setMap(field reflect.Value, tag reflect.StructTag, value string, rounding RoundingMode) error {
+	if isScalarSlice(field.Type().Elem()) && !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return setMapOfSlices(field, tag, value, rounding)
+	}
+	if !isScalar(field.Type().Elem()) {
+		ptr := reflect.New(field.Type())
+		if err := json.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+			return err
+		}
+		field.Set(ptr.Elem())
+		return nil
+	}
+
+	m := reflect.MakeMap(field.Type())
+	err := parseEntries(value, tag, func(key, entryValue string) error {
+		keyValue := reflect.New(field.Type().Key()).Elem()
+		if err := setValue(keyValue, "", key); err != nil {
+			return err
+		}
+		elemValue := reflect.New(field.Type().Elem()).Elem()
+		if err := setRoundedValue(elemValue, tag, entryValue, rounding); err != nil {
+			return err
+		}
+		m.SetMapIndex(keyValue, elemValue)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	field.Set(m)
+	return nil
+}
+
+// setMapOfSlices sets a map of scalar slices from key=value pairs, appending the values of repeated keys.
+func setMapOfSlices(field reflect.Value, tag reflect.StructTag, value string, rounding RoundingMode) error {
+	m := reflect.MakeMap(field.Type())
+	err := parseEntries(value, tag, func(key, entryValue string) error {
+		keyValue := reflect.New(field.Type().Key()).Elem()
+		if err := setValue(keyValue, "", key); err != nil {
+			return err
+		}
+		elemValue := reflect.New(field.Type().Elem().Elem()).Elem()
+		if err := setRoundedValue(elemValue, tag, entryValue, rounding); err != nil {
+			return err
+		}
+		slice := m.MapIndex(keyValue)
+		if !slice.IsValid() {
+			slice = reflect.MakeSlice(field.Type().Elem(), 0, 1)
+		}
+		m.SetMapIndex(keyValue, reflect.Append(slice, elemValue))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	field.Set(m)
+	return nil
+}
+
+// setOrderedMap sets a slice of key-value structs like OrderedMap from key=value pairs in their order.
+func setOrderedMap(field reflect.Value, tag reflect.StructTag, value string, rounding RoundingMode) error {
+	slice := reflect.MakeSlice(field.Type(), 0, 0)
+	err := parseEntries(value, tag, func(key, entryValue string) error {
+		pair := reflect.New(field.Type().Elem()).Elem()
+		if err := setValue(pair.FieldByName("Key"), "", key); err != nil {
+			return err
+		}
+		if err := setRoundedValue(pair.FieldByName("Value"), tag, entryValue, rounding); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, pair)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	field.Set(slice)
 	return nil
 }
+
+// isKeyValue reports whether a type is a struct of a Key and a Value field like KV, parsed from key=value pairs.
+func isKeyValue(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.NumField() != 2 {
+		return false
+	}
+	key, hasKey := typ.FieldByName("Key")
+	_, hasValue := typ.FieldByName("Value")
+	return hasKey && hasValue && isScalar(key.Type)
+}
+
+// parseEntries calls set with the unescaped key and value of each key=value pair of a value, in order.
+// Keys without a value get the value of the mapdefault tag if present, e.g. b for a=30s,b with mapdefault:"1m".
+func parseEntries(value string, tag reflect.StructTag, set func(key, value string) error) error {
+	delim := sliceDelimiter(tag)
+	for i, entry := range splitEscaped(value, delim) {
+		rawKey, rawValue, ok := cutEscaped(entry, "=")
+		if !ok {
+			defaultValue, ok := tag.Lookup("mapdefault")
+			if !ok {
+				return fmt.Errorf("entry %d %q: expected key=value", i, entry)
+			}
+			key := unescape(entry, delim)
+			if err := set(key, defaultValue); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			continue
+		}
+		// Unescaped = in values are kept, e.g. url=http://a?x=1, and a= sets an empty value.
+		key := unescape(rawKey, delim)
+		if err := set(key, unescape(rawValue, delim)); err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// isScalar reports whether values of the type are parsed from a single string like numbers and strings.
+func isScalar(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isScalarSlice reports whether a type is a slice of scalar values like []int.
+func isScalarSlice(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && isScalar(typ.Elem())
+}
+
+// isNumeric reports whether values of the type, or the type it points to, are numbers or durations.
+func isNumeric(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// setMatrix sets a nested slice field like [][]string from rows separated by the rowdelim tag (; by default)
+// with columns separated by the coldelim tag (, by default).
+func setMatrix(field reflect.Value, tag reflect.StructTag, value string) error {
+	rows := splitElements(value, tagDelimiter(tag, "rowdelim", ";"))
+	colDelim := tagDelimiter(tag, "coldelim", ",")
+
+	matrix := reflect.MakeSlice(field.Type(), len(rows), len(rows))
+	columnCount := -1
+	for i, row := range rows {
+		columns := strings.Split(row, colDelim)
+		if columnCount == -1 {
+			columnCount = len(columns)
+		} else if !AllowRaggedRows && len(columns) != columnCount {
+			return fmt.Errorf("row %d has %d columns, expected %d", i, len(columns), columnCount)
+		}
+
+		rowValue := reflect.MakeSlice(field.Type().Elem(), len(columns), len(columns))
+		trim := isNumeric(field.Type().Elem().Elem())
+		for j, column := range columns {
+			if trim {
+				column = strings.TrimSpace(column)
+			}
+			if err := setValue(rowValue.Index(j), "", column); err != nil {
+				return fmt.Errorf("row %d column %d: %w", i, j, err)
+			}
+		}
+		matrix.Index(i).Set(rowValue)
+	}
+	field.Set(matrix)
+	return nil
+}
+
+// splitElements splits value by delim, dropping trailing empty elements, e.g. trailing newlines.
+func splitElements(value, delim string) []string {
+	elements := strings.Split(value, delim)
+	for len(elements) > 0 && elements[len(elements)-1] == "" {
+		elements = elements[:len(elements)-1]
+	}
+	return elements
+}
+
+// splitEscaped splits a value by a separator not escaped by a backslash, dropping trailing empty parts.
+// The escape sequences are kept in the parts.
+func splitEscaped(value, sep string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\':
+			i++
+		case strings.HasPrefix(value[i:], sep):
+			parts = append(parts, value[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	parts = append(parts, value[start:])
+	for len(parts) > 0 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	return parts
+}
+
+// cutEscaped slices a value around the first separator not escaped by a backslash, keeping an empty remainder.
+// The escape sequences are kept in both parts.
+func cutEscaped(value, sep string) (before, after string, found bool) {
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\':
+			i++
+		case strings.HasPrefix(value[i:], sep):
+			return value[:i], value[i+len(sep):], true
+		}
+	}
+	return value, "", false
+}
+
+// unescape resolves the escape sequences \\, \= and \ followed by the delimiter in a map key or value.
+// Other backslashes, including a trailing one, are kept as they are.
+func unescape(value, delim string) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			rest := value[i+1:]
+			switch {
+			case rest[0] == '\\' || rest[0] == '=':
+				b.WriteByte(rest[0])
+				i++
+				continue
+			case strings.HasPrefix(rest, delim):
+				b.WriteString(delim)
+				i += len(delim)
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// sliceDelimiter returns the delimiter of slice elements from the delim tag, a comma by default.
+func sliceDelimiter(tag reflect.StructTag) string {
+	return tagDelimiter(tag, "delim", ",")
+}
+
+// tagDelimiter returns the delimiter of the struct tag name, or defaultDelim if it is not set.
+// Escape sequences like \n and \t are interpreted.
+func tagDelimiter(tag reflect.StructTag, name, defaultDelim string) string {
+	delim, ok := tag.Lookup(name)
+	if !ok || delim == "" {
+		return defaultDelim
+	}
+	if unescaped, err := strconv.Unquote(`"` + delim + `"`); err == nil {
+		return unescaped
+	}
+	return delim
+}
+
+// unquote removes a single pair of matching surrounding quotes (" or ') from value.
+func unquote(value string) string {
+	if len(value) >= 2 && value[0] == value[len(value)-1] && (value[0] == '"' || value[0] == '\'') {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
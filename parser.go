@@ -0,0 +1,87 @@
+package envflagparser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync/atomic"
+)
+
+// Parser parses configs like ParseConfig and keeps track of the stage each field was resolved from.
+type Parser struct {
+	// FlagValuePrefix is stripped from the values of string flags, e.g. for CLI wrappers prepending a namespace
+	// to values they pass on. Values without the prefix are kept as they are.
+	FlagValuePrefix string
+
+	sources map[string]Stage
+	frozen  atomic.Bool
+}
+
+// ErrFrozen is returned by Parse after the parser was frozen.
+var ErrFrozen = errors.New("parser is frozen, the config cannot be parsed again")
+
+// NewParser creates a Parser.
+func NewParser() *Parser {
+	return &Parser{sources: make(map[string]Stage)}
+}
+
+// Parse parses configuration values from flags and environment variables into the provided struct like ParseConfig.
+// It returns ErrFrozen after Freeze was called.
+func (p *Parser) Parse(configStruct interface{}) error {
+	if p.frozen.Load() {
+		return ErrFrozen
+	}
+	fileValues, err := readEnvFile(EnvFile)
+	if err != nil {
+		return err
+	}
+	result, err := registerAndParse(configStruct, parseOptions{dotenv: fileValues, flagValuePrefix: p.FlagValuePrefix})
+	if err != nil {
+		return err
+	}
+	p.sources = result.Sources
+	return nil
+}
+
+// Freeze locks the parser after startup, so later calls of Parse fail fast instead of reconfiguring
+// a running process. The sources of the last parse are kept for LogResolved.
+func (p *Parser) Freeze() {
+	p.frozen.Store(true)
+}
+
+// LogResolved writes the name, source stage and value of each field of the provided struct after a parse
+// to w, sorted by field path, e.g. for startup logs. Values of fields tagged secret are redacted.
+func (p *Parser) LogResolved(configStruct interface{}, w io.Writer) {
+	fields, err := collectFields(reflect.Indirect(reflect.ValueOf(configStruct)))
+	if err != nil {
+		fmt.Fprintf(w, "envflagparser: %v\n", err)
+		return
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+
+	for _, f := range fields {
+		if f.Tag.Get("catchall") == "true" {
+			continue
+		}
+		value, err := formatEnvValue(f.Value, f.Tag)
+		if err != nil {
+			value = fmt.Sprint(f.Value.Interface())
+		}
+		if isSecret(f.Tag) {
+			value = redacted
+		}
+		fmt.Fprintf(w, "%s=%s (%s)\n", f.Path, value, p.sources[f.Path])
+	}
+}
+
+// redacted replaces the values of secret fields in output.
+const redacted = "******"
+
+// isSecret reports whether a field is tagged as secret, so its value is redacted in output.
+func isSecret(tag reflect.StructTag) bool {
+	return tag.Get("secret") == "true"
+}
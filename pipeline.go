@@ -0,0 +1,196 @@
+package envflagparser
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Stage is a step of the resolution pipeline.
+//
+// The value of a field is resolved by applying the enabled stages in the order
+// default, file, env and flag, a later stage overwriting the value of an earlier one.
+// If PrioritiseEnv is true, the env stage is applied after the flag stage instead.
+// Fields left without a value by all stages are set from their fallback tag.
+type Stage int
+
+const (
+	// StageDefault sets fields from their default tag.
+	StageDefault Stage = 1 << iota
+	// StageFile sets fields from the dotenv file EnvFile, keyed by environment variable name.
+	StageFile
+	// StageEnv sets fields from environment variables.
+	StageEnv
+	// StageFlag sets fields from flags explicitly set on the command line.
+	StageFlag
+)
+
+// Stages defines the enabled stages of the resolution pipeline.
+var Stages = StageDefault | StageFile | StageEnv | StageFlag
+
+// FlagsEnv defines the environment variable disabling the flag stage at runtime if set to off or false,
+// e.g. ENVFLAG_FLAGS=off in production, making the process env-only without code changes.
+// Flags are then neither registered nor parsed. It is ignored if empty.
+var FlagsEnv = "ENVFLAG_FLAGS"
+
+// UnsetSentinel defines a value treated as not provided when read from the dotenv file, an environment variable
+// or a flag, e.g. __UNSET__ in templated env files that cannot omit a key. It is disabled if empty.
+var UnsetSentinel = ""
+
+// EnvFile defines the path of the dotenv file read by the file stage. It is skipped if empty or missing.
+var EnvFile = ""
+
+// StrictEnvFile defines whether parsing fails if the dotenv file has keys not mapped to the environment variable
+// of any field or its aliases, e.g. to catch typos like PROT=8080 or leftovers of removed fields.
+var StrictEnvFile = false
+
+// String returns the name of the stage.
+func (s Stage) String() string {
+	switch s {
+	case StageDefault:
+		return "default"
+	case StageFile:
+		return "file"
+	case StageEnv:
+		return "env"
+	case StageFlag:
+		return "flag"
+	}
+	return "none"
+}
+
+// enabledStages returns Stages without the flag stage if it is disabled by FlagsEnv.
+func enabledStages() Stage {
+	if FlagsEnv == "" {
+		return Stages
+	}
+	value, _ := lookupEnv(FlagsEnv)
+	if enabled, err := strconv.ParseBool(value); strings.EqualFold(value, "off") || (err == nil && !enabled) {
+		return Stages &^ StageFlag
+	}
+	return Stages
+}
+
+// pipeline returns the stages in the order they are applied, the env stage last if prioritiseEnv is set.
+func pipeline(prioritiseEnv bool) []Stage {
+	if prioritiseEnv {
+		return []Stage{StageDefault, StageFile, StageFlag, StageEnv}
+	}
+	return []Stage{StageDefault, StageFile, StageEnv, StageFlag}
+}
+
+// stageValues holds the values provided for a field by each stage.
+type stageValues map[Stage]string
+
+// fileSource looks up the value of a field for the file stage.
+type fileSource func(f configField) (string, bool)
+
+// envFileSource returns a fileSource of dotenv values keyed by environment variable name.
+func envFileSource(fileValues map[string]string) fileSource {
+	return func(f configField) (string, bool) {
+		if f.EnvKey == "" {
+			return "", false
+		}
+		value, ok := fileValues[f.EnvKey]
+		return value, ok
+	}
+}
+
+// lookupStageValues collects the default, file and env values of a field.
+func lookupStageValues(f configField, defaultValue string, file fileSource) (stageValues, error) {
+	values := make(stageValues)
+	if defaultValue != "" {
+		values[StageDefault] = defaultValue
+	}
+	if file != nil {
+		if fileValue, ok := file(f); ok {
+			values[StageFile] = fileValue
+		}
+	}
+	envValue, ok, err := lookupFieldEnv(f)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		values[StageEnv] = envValue
+	}
+	return values, nil
+}
+
+// resolve returns the value of the last enabled stage of the pipeline providing one, and that stage.
+// The stage is zero if no stage provided a value. Values equal to UnsetSentinel are not considered provided,
+// neither are empty values for fields tagged emptydefault, so they keep their default.
+func (values stageValues) resolve(tag reflect.StructTag, prioritiseEnv bool) (string, Stage) {
+	var value string
+	var stage Stage
+	stages := enabledStages()
+	for _, s := range pipeline(prioritiseEnv) {
+		v, ok := values[s]
+		if (v == "" && isEmptyDefault(tag)) || isUnsetSentinel(v) {
+			continue
+		}
+		if ok && stages&s != 0 {
+			value, stage = v, s
+		}
+	}
+	return value, stage
+}
+
+// readEnvFile reads the values of the dotenv file at path if the file stage is enabled, nil if none.
+func readEnvFile(path string) (map[string]string, error) {
+	if path == "" || enabledStages()&StageFile == 0 {
+		return nil, nil
+	}
+	values, err := readDotenvFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// checkEnvFileKeys returns an error listing the keys of dotenv values not mapped to any field if strict is set.
+func checkEnvFileKeys(fields []configField, fileValues map[string]string, strict bool) error {
+	if !strict {
+		return nil
+	}
+	mapped := make(map[string]bool)
+	for _, f := range fields {
+		claimEnv(mapped, f)
+	}
+	var unmapped []string
+	for key := range fileValues {
+		if !mapped[key] {
+			unmapped = append(unmapped, key)
+		}
+	}
+	if len(unmapped) == 0 {
+		return nil
+	}
+	sort.Strings(unmapped)
+	return fmt.Errorf("env file has keys not mapped to any field: %s", strings.Join(unmapped, ", "))
+}
+
+// lookupFallback returns the fallback tag of a field if no stage provided a value for it, or only an empty default.
+func lookupFallback(tag reflect.StructTag, value string, stage Stage) (string, bool) {
+	fallback, ok := tag.Lookup("fallback")
+	if !ok || (stage != 0 && (stage != StageDefault || value != "")) {
+		return "", false
+	}
+	return fallback, true
+}
+
+// isUnsetSentinel reports whether a value is the UnsetSentinel, treated as not provided.
+func isUnsetSentinel(value string) bool {
+	return UnsetSentinel != "" && value == UnsetSentinel
+}
+
+// isEmptyDefault reports whether empty values of a field are ignored in favour of its default.
+func isEmptyDefault(tag reflect.StructTag) bool {
+	return tag.Get("emptydefault") == "true"
+}
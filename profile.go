@@ -0,0 +1,65 @@
+package envflagparser
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ProfileEnv defines the environment variable selecting the active profile, e.g. dev or prod.
+// The env, default and usage tags of the active profile like default@prod take precedence over the base tags.
+// Profiles are disabled if empty.
+var ProfileEnv = "APP_PROFILE"
+
+// Environment defines the active environment name selecting defaults of the defaultenv tag,
+// e.g. defaultenv:"prod=:443,dev=:8080". If empty, it is read from EnvironmentEnv.
+var Environment = ""
+
+// EnvironmentEnv defines the environment variable holding the active environment name if Environment is empty.
+var EnvironmentEnv = "APP_ENV"
+
+// activeProfile returns the profile selected by ProfileEnv, empty if none.
+func activeProfile() string {
+	if ProfileEnv == "" {
+		return ""
+	}
+	profile, _ := lookupEnv(ProfileEnv)
+	return profile
+}
+
+// lookupProfileTag returns the value of the struct tag name of the active profile if present,
+// otherwise of the base tag name, or of defaultName if name is empty.
+func lookupProfileTag(tag reflect.StructTag, name, defaultName string) string {
+	if name == "" {
+		name = defaultName
+	}
+	if profile := activeProfile(); profile != "" {
+		if value, ok := tag.Lookup(name + "@" + profile); ok {
+			return value
+		}
+	}
+	return tag.Get(name)
+}
+
+// activeEnvironment returns the environment name set by Environment or EnvironmentEnv, empty if none.
+func activeEnvironment() string {
+	if Environment != "" || EnvironmentEnv == "" {
+		return Environment
+	}
+	environment, _ := lookupEnv(EnvironmentEnv)
+	return environment
+}
+
+// lookupEnvironmentDefault returns the default of the active environment from the comma-separated
+// name=value pairs of the defaultenv tag.
+func lookupEnvironmentDefault(tag reflect.StructTag) (string, bool) {
+	environment := activeEnvironment()
+	if environment == "" {
+		return "", false
+	}
+	for _, entry := range strings.Split(tag.Get("defaultenv"), ",") {
+		if name, value, ok := strings.Cut(entry, "="); ok && strings.TrimSpace(name) == environment {
+			return value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,67 @@
+package envflagparser
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// RoundingMode defines how fractional values are rounded when LenientTypes coerces floats to integer fields.
+type RoundingMode int
+
+const (
+	// RoundDefault selects FloatRounding, it is the zero value of Options.Rounding.
+	RoundDefault RoundingMode = iota
+	// RoundTruncate drops the fractional part, e.g. 2.5 to 2 and -2.5 to -2.
+	RoundTruncate
+	// RoundHalfUp rounds to the nearest integer, halves away from zero, e.g. 2.5 to 3 and -2.5 to -3.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest integer, halves to the even neighbour, e.g. 2.5 to 2 and 3.5 to 4.
+	RoundHalfEven
+)
+
+// FloatRounding defines the rounding of floats coerced to integer fields by LenientTypes, truncating by default.
+// Options.Rounding and the rounding tag of a field, e.g. rounding:"halfeven", take precedence in that order.
+var FloatRounding = RoundTruncate
+
+// String returns the name of the rounding mode as used by the rounding tag.
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundDefault:
+		return "default"
+	case RoundHalfUp:
+		return "halfup"
+	case RoundHalfEven:
+		return "halfeven"
+	}
+	return "truncate"
+}
+
+// round rounds a float to an integer value with the rounding mode.
+func (m RoundingMode) round(value float64) float64 {
+	switch m {
+	case RoundHalfUp:
+		return math.Round(value)
+	case RoundHalfEven:
+		return math.RoundToEven(value)
+	}
+	return math.Trunc(value)
+}
+
+// roundingMode returns the rounding mode of the rounding tag of a field. Fields without one use the provided
+// rounding mode, FloatRounding if it is RoundDefault.
+func roundingMode(tag reflect.StructTag, rounding RoundingMode) (RoundingMode, error) {
+	name, ok := tag.Lookup("rounding")
+	if !ok && rounding != RoundDefault {
+		return rounding, nil
+	}
+	if !ok {
+		return FloatRounding, nil
+	}
+	for _, m := range []RoundingMode{RoundTruncate, RoundHalfUp, RoundHalfEven} {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid rounding tag %q, expected truncate, halfup or halfeven", name)
+}
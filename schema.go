@@ -0,0 +1,169 @@
+package envflagparser
+
+import (
+	"encoding"
+	"encoding/json"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchema returns a JSON Schema of the provided struct for documentation and validation tooling.
+// Properties are keyed by field name and describe the type, usage, default, required, oneof, min,
+// max and maxitems tags of each field. Nested structs are described by nested object schemas.
+// Structs with invalid tags, rejected by ParseConfig, return its error.
+func JSONSchema(config interface{}) ([]byte, error) {
+	schema, err := objectSchema(reflect.Indirect(reflect.ValueOf(config)))
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// objectSchema returns the object schema of a struct value.
+func objectSchema(elem reflect.Value) (map[string]interface{}, error) {
+	fields, err := collectFields(elem)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTags(fields); err != nil {
+		return nil, err
+	}
+	defaults, err := resolveDefaults(fields, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	root := newObjectSchema()
+	for i, f := range fields {
+		// Nested structs are described by the object schema of their path.
+		object := root
+		segments := strings.Split(f.Path, ".")
+		for _, name := range segments[:len(segments)-1] {
+			properties := object["properties"].(map[string]interface{})
+			nested, ok := properties[name].(map[string]interface{})
+			if !ok {
+				nested = newObjectSchema()
+				properties[name] = nested
+			}
+			object = nested
+		}
+
+		schema, err := fieldSchema(f, defaults[i])
+		if err != nil {
+			return nil, err
+		}
+		object["properties"].(map[string]interface{})[f.Name] = schema
+		if isRequired(f.Tag) {
+			required, _ := object["required"].([]string)
+			object["required"] = append(required, f.Name)
+		}
+	}
+	return root, nil
+}
+
+// newObjectSchema returns an object schema without properties.
+func newObjectSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+}
+
+// fieldSchema returns the schema of a field with the constraints of its tags.
+func fieldSchema(f configField, defaultValue string) (map[string]interface{}, error) {
+	if isIndexedStruct(f.Tag) {
+		items, err := objectSchema(reflect.New(f.Type.Elem()).Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	}
+
+	schema := typeSchema(f.Type, f.Tag)
+	if usage := usageTag(f.Tag); usage != "" {
+		schema["description"] = usage
+	}
+	if defaultValue != "" {
+		schema["default"] = schemaValue(f.Type, f.Tag, schema, defaultValue)
+	}
+	if options := oneOfTag(f.Tag); len(options) > 0 {
+		enum := make([]interface{}, len(options))
+		for i, option := range options {
+			enum[i] = schemaValue(f.Type, f.Tag, schema, option)
+		}
+		schema["enum"] = enum
+	}
+	if schema["type"] == "integer" || schema["type"] == "number" {
+		for bound, keyword := range map[string]string{"min": "minimum", "max": "maximum"} {
+			if limit, ok := f.Tag.Lookup(bound); ok {
+				schema[keyword] = schemaValue(f.Type, f.Tag, schema, limit)
+			}
+		}
+	}
+	if maxItems, err := strconv.Atoi(f.Tag.Get("maxitems")); err == nil && schema["type"] != "string" {
+		schema["maxItems"] = maxItems
+	}
+	return schema, nil
+}
+
+// typeSchema returns the schema of the values of a type, values parsed from text are described as strings.
+func typeSchema(typ reflect.Type, tag reflect.StructTag) map[string]interface{} {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if isAtomic(typ) {
+		return typeSchema(atomicValueType(typ), tag)
+	}
+	switch reflect.New(typ).Interface().(type) {
+	case StringSetter, encoding.TextUnmarshaler:
+		return map[string]interface{}{"type": "string"}
+	}
+	if typ == reflect.TypeOf(time.Duration(0)) || typ == reflect.TypeOf(os.FileMode(0)) ||
+		typ == reflect.TypeOf(net.HardwareAddr(nil)) || tag.Get("bitflags") != "" || tag.Get("bytesize") == "true" {
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(typ.Elem(), "")}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(typ.Elem(), "")}
+	}
+	return map[string]interface{}{"type": "string"}
+}
+
+// schemaValue returns a tag value like a default in the JSON type of a schema, falling back to the
+// raw value for strings and values that cannot be parsed.
+func schemaValue(typ reflect.Type, tag reflect.StructTag, schema map[string]interface{}, raw string) interface{} {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch schema["type"] {
+	case "integer", "number", "boolean":
+	case "array":
+		// Elements parsed from text like durations keep the raw list.
+		if items := schema["items"].(map[string]interface{}); items["type"] == "string" && typ.Elem().Kind() != reflect.String {
+			return raw
+		}
+	default:
+		return raw
+	}
+
+	value := reflect.New(typ).Elem()
+	if err := setValue(value, tag, raw); err != nil {
+		return raw
+	}
+	if isAtomic(value.Type()) {
+		value = loadAtomic(value)
+	}
+	return value.Interface()
+}
@@ -0,0 +1,60 @@
+package envflagparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// secretResolvers holds the resolvers registered by RegisterSecretResolver by scheme.
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = make(map[string]func(ref string) (string, error))
+)
+
+// RegisterSecretResolver registers fn to dereference the values of fields tagged secret that start with scheme://,
+// e.g. RegisterSecretResolver("vault", fn) resolving vault://path#key to the secret it names, so secret managers
+// can be integrated without being built in. fn receives the whole reference. Values of other fields and values
+// with other schemes are used as they are. Registering nil removes the resolver.
+func RegisterSecretResolver(scheme string, fn func(ref string) (string, error)) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	if fn == nil {
+		delete(secretResolvers, scheme)
+		return
+	}
+	secretResolvers[scheme] = fn
+}
+
+// lookupSecretResolver returns the resolver for the scheme of the value of a secret field, nil if none.
+func lookupSecretResolver(tag reflect.StructTag, value string) func(ref string) (string, error) {
+	if !isSecret(tag) {
+		return nil
+	}
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return nil
+	}
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	return secretResolvers[scheme]
+}
+
+// isSecretRef reports whether the value of a field is a reference resolved by a secret resolver.
+func isSecretRef(tag reflect.StructTag, value string) bool {
+	return lookupSecretResolver(tag, value) != nil
+}
+
+// resolveSecret returns the secret referenced by the value of a secret field, otherwise the value itself.
+func resolveSecret(tag reflect.StructTag, value string) (string, error) {
+	resolve := lookupSecretResolver(tag, value)
+	if resolve == nil {
+		return value, nil
+	}
+	secret, err := resolve(value)
+	if err != nil {
+		return "", fmt.Errorf("resolve secret: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,77 @@
+package envflagparser
+
+import (
+	"reflect"
+	"sync"
+)
+
+// FieldSetter parses the raw value of a field and sets it.
+type FieldSetter func(field reflect.Value, raw string) error
+
+// fieldSetters holds the setters registered by RegisterFieldSetter by struct type name and field name.
+var (
+	fieldSettersMu sync.RWMutex
+	fieldSetters   = make(map[[2]string]FieldSetter)
+)
+
+// typeParsers holds the parsers registered by RegisterParser by type.
+var (
+	typeParsersMu sync.RWMutex
+	typeParsers   = make(map[reflect.Type]func(value string) (reflect.Value, error))
+)
+
+// RegisterFieldSetter registers fn to parse the values of the field fieldName of the struct type typeName,
+// e.g. RegisterFieldSetter("Config", "Ports", parsePortRange) for one-off formats without defining a type.
+// The type name is unqualified like Config or qualified by package like main.Config.
+//
+// A field setter takes precedence over SetFromString, UnmarshalText and the built-in parsing of the field type.
+// Validation tags like min and max still apply to the set value. Registering nil removes the setter.
+func RegisterFieldSetter(typeName, fieldName string, fn func(field reflect.Value, raw string) error) {
+	fieldSettersMu.Lock()
+	defer fieldSettersMu.Unlock()
+	if fn == nil {
+		delete(fieldSetters, [2]string{typeName, fieldName})
+		return
+	}
+	fieldSetters[[2]string{typeName, fieldName}] = fn
+}
+
+// lookupFieldSetter returns the setter registered for a field, nil if none.
+func lookupFieldSetter(f configField) FieldSetter {
+	if f.Struct == nil {
+		return nil
+	}
+	fieldSettersMu.RLock()
+	defer fieldSettersMu.RUnlock()
+	if fn, ok := fieldSetters[[2]string{f.Struct.Name(), f.Name}]; ok {
+		return fn
+	}
+	return fieldSetters[[2]string{f.Struct.String(), f.Name}]
+}
+
+// RegisterParser registers fn to parse the values of type T, e.g. a Temperature parsing 77F into degrees Celsius,
+// for types that cannot implement SetFromString like types of other packages. It applies wherever a value of T is
+// parsed: fields, pointers to T and elements of slices and maps.
+//
+// A parser takes precedence over SetFromString, UnmarshalText and the built-in parsing of T, field setters
+// registered by RegisterFieldSetter take precedence over it. Registering nil removes the parser.
+func RegisterParser[T any](fn func(value string) (T, error)) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	typeParsersMu.Lock()
+	defer typeParsersMu.Unlock()
+	if fn == nil {
+		delete(typeParsers, typ)
+		return
+	}
+	typeParsers[typ] = func(value string) (reflect.Value, error) {
+		parsed, err := fn(value)
+		return reflect.ValueOf(&parsed).Elem(), err
+	}
+}
+
+// lookupTypeParser returns the parser registered for a type, nil if none.
+func lookupTypeParser(typ reflect.Type) func(value string) (reflect.Value, error) {
+	typeParsersMu.RLock()
+	defer typeParsersMu.RUnlock()
+	return typeParsers[typ]
+}
@@ -0,0 +1,111 @@
+package envflagparser_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type LayeredConfig struct {
+	Host    string        `env:"APPLY_HOST" flag:"host" default:"localhost"`
+	Port    int           `env:"APPLY_PORT" default:"8080"`
+	Timeout time.Duration `env:"APPLY_TIMEOUT"`
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("APPLY_PORT", "9090")
+
+	config := LayeredConfig{Host: "file.example.com", Port: 80, Timeout: time.Minute}
+	if err := envflagparser.ApplyEnv(&config); err != nil {
+		t.Fatalf("Error applying env: %v", err)
+	}
+
+	if config.Host != "file.example.com" {
+		t.Errorf("Expected Host: %s, Got: %s", "file.example.com", config.Host)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+	if config.Timeout != time.Minute {
+		t.Errorf("Expected Timeout: %s, Got: %s", time.Minute, config.Timeout)
+	}
+}
+
+func TestApplyEnvInvalidValue(t *testing.T) {
+	t.Setenv("APPLY_PORT", "eighty")
+
+	var config LayeredConfig
+	if err := envflagparser.ApplyEnv(&config); err == nil {
+		t.Error("Expected error for invalid port")
+	}
+}
+
+type QuotedConfig struct {
+	Port  int     `env:"QUOTED_PORT"`
+	Ratio float64 `env:"QUOTED_RATIO"`
+	Name  string  `env:"QUOTED_NAME"`
+}
+
+func TestUnquoteEnvValues(t *testing.T) {
+	envflagparser.UnquoteEnvValues = true
+	defer func() { envflagparser.UnquoteEnvValues = false }()
+	setArgs(t)
+	t.Setenv("QUOTED_PORT", `"8080"`)
+	t.Setenv("QUOTED_RATIO", `'0.5'`)
+	t.Setenv("QUOTED_NAME", `"app`)
+
+	var config QuotedConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected Port: %d, Got: %d", 8080, config.Port)
+	}
+	if config.Ratio != 0.5 {
+		t.Errorf("Expected Ratio: %f, Got: %f", 0.5, config.Ratio)
+	}
+	if config.Name != `"app` {
+		t.Errorf("Expected unmatched quote to be kept, Got: %s", config.Name)
+	}
+}
+
+func TestQuotedEnvNumberWithoutUnquote(t *testing.T) {
+	setArgs(t)
+	t.Setenv("QUOTED_PORT", `"8080"`)
+
+	var config QuotedConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for quoted number without UnquoteEnvValues")
+	}
+}
+
+type IndirectConfig struct {
+	APIKey string `env:"INDIRECT_KEY" indirect:"true"`
+}
+
+func TestIndirectEnv(t *testing.T) {
+	setArgs(t)
+	t.Setenv("INDIRECT_KEY", "INDIRECT_REAL_SECRET")
+	t.Setenv("INDIRECT_REAL_SECRET", "s3cr3t")
+
+	var config IndirectConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.APIKey != "s3cr3t" {
+		t.Errorf("Expected APIKey: %s, Got: %s", "s3cr3t", config.APIKey)
+	}
+}
+
+func TestIndirectEnvMissingTarget(t *testing.T) {
+	setArgs(t)
+	t.Setenv("INDIRECT_KEY", "INDIRECT_MISSING_SECRET")
+
+	var config IndirectConfig
+	err := envflagparser.ParseConfig(&config)
+	if err == nil || !strings.Contains(err.Error(), "INDIRECT_MISSING_SECRET") {
+		t.Errorf("Expected error naming the missing target, Got: %v", err)
+	}
+}
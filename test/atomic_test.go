@@ -0,0 +1,64 @@
+package envflagparser_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type AtomicConfig struct {
+	Debug   atomic.Bool   `env:"ATOMIC_DEBUG" flag:"debug"`
+	Limit   atomic.Int64  `env:"ATOMIC_LIMIT" default:"10" max:"100"`
+	Workers atomic.Uint32 `env:"ATOMIC_WORKERS"`
+}
+
+func TestAtomicFromEnv(t *testing.T) {
+	setArgs(t)
+	t.Setenv("ATOMIC_DEBUG", "true")
+	t.Setenv("ATOMIC_LIMIT", "42")
+
+	var config AtomicConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if !config.Debug.Load() {
+		t.Errorf("Expected Debug: %t, Got: %t", true, config.Debug.Load())
+	}
+	if config.Limit.Load() != 42 {
+		t.Errorf("Expected Limit: %d, Got: %d", 42, config.Limit.Load())
+	}
+	if config.Workers.Load() != 0 {
+		t.Errorf("Expected Workers: %d, Got: %d", 0, config.Workers.Load())
+	}
+	if limit := envflagparser.ToMap(&config)["Limit"]; limit != int64(42) {
+		t.Errorf("Expected Limit in map: %d, Got: %v", 42, limit)
+	}
+}
+
+func TestAtomicFromFlagAndDefault(t *testing.T) {
+	setArgs(t, "-debug")
+
+	var config AtomicConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if !config.Debug.Load() {
+		t.Errorf("Expected Debug: %t, Got: %t", true, config.Debug.Load())
+	}
+	if config.Limit.Load() != 10 {
+		t.Errorf("Expected Limit: %d, Got: %d", 10, config.Limit.Load())
+	}
+}
+
+func TestAtomicValidation(t *testing.T) {
+	setArgs(t)
+	t.Setenv("ATOMIC_LIMIT", "1000")
+
+	var config AtomicConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for atomic value above max")
+	}
+}
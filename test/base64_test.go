@@ -0,0 +1,73 @@
+package envflagparser_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type UpstreamConfig struct {
+	Host    string            `json:"host"`
+	Ports   []int             `json:"ports"`
+	Headers map[string]string `json:"headers"`
+}
+
+type Base64Config struct {
+	Upstream UpstreamConfig `env:"BASE64_UPSTREAM" format:"base64json"`
+}
+
+func TestBase64JSON(t *testing.T) {
+	upstream := UpstreamConfig{Host: "api.example.com", Ports: []int{80, 443}, Headers: map[string]string{"X-Env": "prod"}}
+	data, err := json.Marshal(upstream)
+	if err != nil {
+		t.Fatalf("Error encoding JSON: %v", err)
+	}
+
+	setArgs(t)
+	t.Setenv("BASE64_UPSTREAM", base64.StdEncoding.EncodeToString(data))
+	var config Base64Config
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if !reflect.DeepEqual(config.Upstream, upstream) {
+		t.Errorf("Expected Upstream: %v, Got: %v", upstream, config.Upstream)
+	}
+
+	// MarshalEnv renders the field back as base64-encoded JSON.
+	env, err := envflagparser.MarshalEnv(&config)
+	if err != nil {
+		t.Fatalf("Error marshalling config: %v", err)
+	}
+	encoded := strings.Trim(strings.TrimPrefix(strings.TrimSpace(string(env)), "BASE64_UPSTREAM="), `"`)
+	var decoded UpstreamConfig
+	if err := envflagparser.DecodeBase64JSON(&decoded, encoded); err != nil {
+		t.Fatalf("Error decoding config: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, upstream) {
+		t.Errorf("Expected decoded config: %v, Got: %v", upstream, decoded)
+	}
+
+	// URL-safe encodings without padding are accepted too.
+	var unpadded UpstreamConfig
+	if err := envflagparser.DecodeBase64JSON(&unpadded, base64.RawURLEncoding.EncodeToString(data)); err != nil || !reflect.DeepEqual(unpadded, upstream) {
+		t.Errorf("Expected unpadded config: %v, Got: %v (%v)", upstream, unpadded, err)
+	}
+}
+
+func TestBase64JSONErrors(t *testing.T) {
+	setArgs(t)
+	tests := map[string]string{
+		"not base64!": "invalid base64",
+		base64.StdEncoding.EncodeToString([]byte("{")): "invalid JSON in base64 value",
+	}
+	for value, expected := range tests {
+		t.Setenv("BASE64_UPSTREAM", value)
+		if err := envflagparser.ParseConfig(&Base64Config{}); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q for %q, Got: %v", expected, value, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package envflagparser_test
+
+import (
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+func TestByteSize(t *testing.T) {
+	type CacheSizeConfig struct {
+		Size  uint64 `env:"BYTESIZE_SIZE" flag:"size" bytesize:"true"`
+		Limit int64  `env:"BYTESIZE_LIMIT" bytesize:"true" default:"1KiB"`
+	}
+
+	for value, expected := range map[string]uint64{
+		"10MB":   10000000,
+		"1.5GiB": 1610612736,
+		"2.5e6":  2500000,
+		"1.1gb":  1100000000,
+		"512":    512,
+		"64 KB":  64000,
+	} {
+		setArgs(t, "-size", value)
+		var config CacheSizeConfig
+		if err := envflagparser.ParseConfig(&config); err != nil {
+			t.Fatalf("Error parsing config: %v", err)
+		}
+		if config.Size != expected {
+			t.Errorf("Expected Size for %s: %d, Got: %d", value, expected, config.Size)
+		}
+		if config.Limit != 1024 {
+			t.Errorf("Expected Limit: %d, Got: %d", 1024, config.Limit)
+		}
+	}
+
+	for _, value := range []string{"10XB", "1.5e", "0.5B", "1/2MB", "-1MB", "MB"} {
+		setArgs(t)
+		t.Setenv("BYTESIZE_SIZE", value)
+		var config CacheSizeConfig
+		if err := envflagparser.ParseConfig(&config); err == nil {
+			t.Errorf("Expected error for %s, Got: %d", value, config.Size)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package envflagparser_test
+
+import (
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type CatchAllConfig struct {
+	Host  string            `env:"APP_HOST"`
+	Port  int               `env:"APP_PORT"`
+	Extra map[string]string `env:"APP_" catchall:"true"`
+}
+
+func TestCatchAll(t *testing.T) {
+	setArgs(t)
+	t.Setenv("APP_HOST", "example.com")
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_FOO", "bar")
+	t.Setenv("OTHER_FOO", "baz")
+
+	var config CatchAllConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Host != "example.com" {
+		t.Errorf("Expected Host: %s, Got: %s", "example.com", config.Host)
+	}
+	if config.Extra["APP_FOO"] != "bar" {
+		t.Errorf("Expected APP_FOO in catch-all, Got: %v", config.Extra)
+	}
+	for _, key := range []string{"APP_HOST", "APP_PORT", "OTHER_FOO"} {
+		if _, ok := config.Extra[key]; ok {
+			t.Errorf("Expected %s not to be in catch-all, Got: %v", key, config.Extra)
+		}
+	}
+}
+
+func TestCatchAllWrongType(t *testing.T) {
+	setArgs(t)
+
+	var config struct {
+		Extra []string `env:"APP_" catchall:"true"`
+	}
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for catch-all field that is not a map[string]string")
+	}
+}
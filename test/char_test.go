@@ -0,0 +1,58 @@
+package envflagparser_test
+
+import (
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type CharConfig struct {
+	Delimiter rune `env:"CHAR_DELIM" flag:"delim" default:";" char:"true"`
+	Separator byte `env:"CHAR_SEP" char:"true"`
+}
+
+func TestCharField(t *testing.T) {
+	setArgs(t)
+	t.Setenv("CHAR_DELIM", ",")
+	t.Setenv("CHAR_SEP", "|")
+
+	var config CharConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Delimiter != ',' {
+		t.Errorf("Expected Delimiter: %q, Got: %q", ',', config.Delimiter)
+	}
+	if config.Separator != '|' {
+		t.Errorf("Expected Separator: %q, Got: %q", '|', config.Separator)
+	}
+}
+
+func TestCharFieldCodePoint(t *testing.T) {
+	setArgs(t)
+	t.Setenv("CHAR_DELIM", "44")
+	t.Setenv("CHAR_SEP", "124")
+
+	var config CharConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Delimiter != ',' {
+		t.Errorf("Expected Delimiter: %q, Got: %q", ',', config.Delimiter)
+	}
+	if config.Separator != '|' {
+		t.Errorf("Expected Separator: %q, Got: %q", '|', config.Separator)
+	}
+}
+
+func TestCharFieldFlag(t *testing.T) {
+	setArgs(t, "-delim", "\t")
+
+	var config CharConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Delimiter != '\t' {
+		t.Errorf("Expected Delimiter: %q, Got: %q", '\t', config.Delimiter)
+	}
+}
@@ -0,0 +1,37 @@
+package envflagparser_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+func TestConfigContext(t *testing.T) {
+	setArgs(t)
+	t.Setenv("STAGE_VALUE", "env")
+
+	var config StageConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	ctx := envflagparser.WithConfig(context.Background(), &config)
+	fromContext, ok := envflagparser.FromContext[StageConfig](ctx)
+	if !ok || fromContext != &config {
+		t.Errorf("Expected config: %p, Got: %p", &config, fromContext)
+	}
+
+	if _, ok := envflagparser.FromContext[OptionsConfig](ctx); ok {
+		t.Error("Expected no config of another type")
+	}
+	if _, ok := envflagparser.FromContext[StageConfig](context.Background()); ok {
+		t.Error("Expected no config in an empty context")
+	}
+
+	ctx = envflagparser.WithConfig(context.Background(), config)
+	fromContext, ok = envflagparser.FromContext[StageConfig](ctx)
+	if !ok || fromContext.Value != "env" {
+		t.Errorf("Expected Value: %s, Got: %v", "env", fromContext)
+	}
+}
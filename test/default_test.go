@@ -0,0 +1,158 @@
+package envflagparser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type URLConfig struct {
+	Host string `env:"URL_HOST" flag:"host" default:"localhost"`
+	Port int    `env:"URL_PORT" flag:"port" default:"8080"`
+	URL  string `env:"URL_URL" flag:"url" default:"http://${host}:${port}"`
+}
+
+func TestDefaultInterpolation(t *testing.T) {
+	setArgs(t)
+
+	var config URLConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.URL != "http://localhost:8080" {
+		t.Errorf("Expected URL: %s, Got: %s", "http://localhost:8080", config.URL)
+	}
+}
+
+func TestDefaultInterpolationFromEnv(t *testing.T) {
+	setArgs(t)
+	t.Setenv("URL_HOST", "example.com")
+
+	var config URLConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.URL != "http://example.com:8080" {
+		t.Errorf("Expected URL: %s, Got: %s", "http://example.com:8080", config.URL)
+	}
+}
+
+func TestDefaultInterpolationCycle(t *testing.T) {
+	setArgs(t)
+
+	var config struct {
+		A string `flag:"a" default:"${b}"`
+		B string `flag:"b" default:"${a}"`
+	}
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for cyclic default references")
+	}
+}
+
+type DefaultMapConfig struct {
+	Host    string `env:"DEFMAP_HOST" flag:"host" default:"localhost"`
+	Port    int    `env:"DEFMAP_PORT" flag:"port"`
+	Region  string `env:"DEFMAP_REGION" default:"eu"`
+	Address string `flag:"address" default:"${host}:${port}"`
+}
+
+func TestParseConfigWithDefaultMap(t *testing.T) {
+	setArgs(t)
+	t.Setenv("DEFMAP_REGION", "us")
+
+	var config DefaultMapConfig
+	err := envflagparser.ParseConfigWithDefaultMap(&config, map[string]string{
+		"Port":   "9090",
+		"Region": "ap",
+	})
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	expected := DefaultMapConfig{Host: "localhost", Port: 9090, Region: "us", Address: "localhost:9090"}
+	if config != expected {
+		t.Errorf("Expected config: %+v, Got: %+v", expected, config)
+	}
+}
+
+func TestParseConfigWithDefaultFile(t *testing.T) {
+	setArgs(t, "-host", "cli.example.com")
+	t.Setenv("DEFMAP_REGION", "us")
+
+	path := filepath.Join(t.TempDir(), "defaults.env")
+	content := "DEFMAP_HOST=shared.example.com\nDEFMAP_PORT=9090\nDEFMAP_REGION=ap\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing defaults file: %v", err)
+	}
+
+	var config DefaultMapConfig
+	if err := envflagparser.ParseConfigWithDefaultFile(&config, path); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	expected := DefaultMapConfig{Host: "cli.example.com", Port: 9090, Region: "us", Address: "shared.example.com:9090"}
+	if config != expected {
+		t.Errorf("Expected config: %+v, Got: %+v", expected, config)
+	}
+}
+
+func TestParseConfigWithMissingDefaultFile(t *testing.T) {
+	setArgs(t)
+
+	var config DefaultMapConfig
+	if err := envflagparser.ParseConfigWithDefaultFile(&config, filepath.Join(t.TempDir(), "missing.env")); err == nil {
+		t.Error("Expected error for missing defaults file")
+	}
+}
+
+type FallbackConfig struct {
+	Region  string `env:"FALLBACK_REGION" flag:"region" fallback:"eu-west-1"`
+	Zone    string `env:"FALLBACK_ZONE" default:"a" fallback:"b"`
+	Retries int    `env:"FALLBACK_RETRIES" fallback:"3"`
+}
+
+func TestFallback(t *testing.T) {
+	setArgs(t)
+
+	var config FallbackConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Region != "eu-west-1" {
+		t.Errorf("Expected Region: %s, Got: %s", "eu-west-1", config.Region)
+	}
+	if config.Zone != "a" {
+		t.Errorf("Expected Zone: %s, Got: %s", "a", config.Zone)
+	}
+	if config.Retries != 3 {
+		t.Errorf("Expected Retries: %d, Got: %d", 3, config.Retries)
+	}
+
+	setArgs(t, "-region", "us-east-1")
+	t.Setenv("FALLBACK_RETRIES", "5")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Region != "us-east-1" {
+		t.Errorf("Expected Region: %s, Got: %s", "us-east-1", config.Region)
+	}
+	if config.Retries != 5 {
+		t.Errorf("Expected Retries: %d, Got: %d", 5, config.Retries)
+	}
+}
+
+func TestFallbackAfterFlagDefaults(t *testing.T) {
+	setArgs(t)
+	envflagparser.ExplicitFlagsOnly = false
+	defer func() { envflagparser.ExplicitFlagsOnly = true }()
+
+	var config FallbackConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Region != "eu-west-1" {
+		t.Errorf("Expected Region: %s, Got: %s", "eu-west-1", config.Region)
+	}
+}
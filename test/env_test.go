@@ -2,6 +2,9 @@ package envflagparser_test
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -42,3 +45,241 @@ func TestParseConfig(t *testing.T) {
 		t.Errorf("Expected EnableLogs: %t, Got: %t", expectedConfig.EnableLogs, parsedConfig.EnableLogs)
 	}
 }
+
+func TestCaseInsensitiveEnv(t *testing.T) {
+	defer func(old bool) { envflagparser.CaseInsensitiveEnv = old }(envflagparser.CaseInsensitiveEnv)
+	envflagparser.CaseInsensitiveEnv = true
+	setArgs(t)
+	t.Setenv("Case_Host", "example.com")
+	t.Setenv("case_extra", "value")
+
+	var config struct {
+		Host  string            `env:"CASE_HOST"`
+		Extra map[string]string `env:"CASE_" catchall:"true"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Host != "example.com" {
+		t.Errorf("Expected Host: %s, Got: %s", "example.com", config.Host)
+	}
+	if _, ok := config.Extra["Case_Host"]; ok {
+		t.Errorf("Expected claimed Case_Host not to be in catch-all, Got: %v", config.Extra)
+	}
+	if config.Extra["case_extra"] != "value" {
+		t.Errorf("Expected case_extra in catch-all, Got: %v", config.Extra)
+	}
+}
+
+func TestCaseSensitiveEnv(t *testing.T) {
+	defer func(old bool) { envflagparser.CaseInsensitiveEnv = old }(envflagparser.CaseInsensitiveEnv)
+	envflagparser.CaseInsensitiveEnv = false
+	setArgs(t)
+	t.Setenv("Case_Host", "example.com")
+
+	var config struct {
+		Host string `env:"CASE_HOST"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Host != "" {
+		t.Errorf("Expected Host to be empty, Got: %s", config.Host)
+	}
+}
+
+func TestParseConfigStripPrefix(t *testing.T) {
+	setArgs(t)
+	t.Setenv("SVC_PORT", "9090")
+	t.Setenv("HOST", "shared.example.com")
+
+	var config struct {
+		Port int    `env:"PORT" default:"8080"`
+		Host string `env:"HOST" default:"localhost"`
+	}
+	if err := envflagparser.ParseConfigStripPrefix(&config, "SVC_"); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+	if config.Host != "localhost" {
+		t.Errorf("Expected unprefixed HOST to be ignored, Host: %s, Got: %s", "localhost", config.Host)
+	}
+}
+
+func TestParseConfigStripPrefixReferences(t *testing.T) {
+	setArgs(t)
+	setEnvFile(t, "NAME=service\n")
+	t.Setenv("SVC_HOST", "svc.example.com")
+	t.Setenv("SVC_EXTRA_REGION", "eu")
+	t.Setenv("EXTRA_ZONE", "a")
+
+	var config struct {
+		Host  string            `env:"HOST" default:"localhost"`
+		URL   string            `env:"URL" default:"http://${HOST}:8080"`
+		Name  string            `env:"NAME"`
+		Extra map[string]string `env:"EXTRA_" catchall:"true"`
+	}
+	if err := envflagparser.ParseConfigStripPrefix(&config, "SVC_"); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.URL != "http://svc.example.com:8080" {
+		t.Errorf("Expected URL: %s, Got: %s", "http://svc.example.com:8080", config.URL)
+	}
+	if config.Name != "service" {
+		t.Errorf("Expected Name from the dotenv file: %s, Got: %s", "service", config.Name)
+	}
+	expected := map[string]string{"EXTRA_REGION": "eu"}
+	if !reflect.DeepEqual(config.Extra, expected) {
+		t.Errorf("Expected Extra: %v, Got: %v", expected, config.Extra)
+	}
+}
+
+func TestEnvKeys(t *testing.T) {
+	var config struct {
+		Port     int               `env:"KEYS_PORT" flag:"port"`
+		Host     string            `env:"KEYS_HOST" envalias:"OLD_KEYS_HOST"`
+		Verbose  bool              `flag:"verbose"`
+		Extra    map[string]string `env:"KEYS_" catchall:"true"`
+		Database struct {
+			Host     string `env:"HOST"`
+			MaxConns int
+			Region   string `env:"/KEYS_REGION"`
+		} `prefix:"KEYS_DB_"`
+	}
+
+	keys := envflagparser.EnvKeys(&config)
+	expected := []string{"KEYS_PORT", "KEYS_HOST", "OLD_KEYS_HOST", "KEYS_DB_HOST", "KEYS_DB_MAX_CONNS", "KEYS_REGION"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys: %v, Got: %v", expected, keys)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	type PathConfig struct {
+		DataDir  string `env:"EXPAND_DATA_DIR" expandhome:"true"`
+		CacheDir string `env:"EXPAND_CACHE_DIR" expandhome:"true"`
+		Literal  string `env:"EXPAND_LITERAL"`
+	}
+
+	setArgs(t)
+	t.Setenv("EXPAND_DATA_DIR", "~/sub/dir")
+	t.Setenv("EXPAND_CACHE_DIR", "~")
+	t.Setenv("EXPAND_LITERAL", "~/sub/dir")
+
+	var config PathConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := filepath.Join(home, "sub", "dir"); config.DataDir != expected {
+		t.Errorf("Expected DataDir: %s, Got: %s", expected, config.DataDir)
+	}
+	if config.CacheDir != home {
+		t.Errorf("Expected CacheDir: %s, Got: %s", home, config.CacheDir)
+	}
+	if config.Literal != "~/sub/dir" {
+		t.Errorf("Expected Literal: %s, Got: %s", "~/sub/dir", config.Literal)
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	type TransformConfig struct {
+		Level  string `env:"TRANSFORM_LEVEL" transforms:"trim,lower"`
+		Region string `env:"TRANSFORM_REGION" transforms:"upper,trim"`
+		Path   string `env:"TRANSFORM_PATH" transforms:"expandenv,nodots"`
+		Port   int    `env:"TRANSFORM_PORT" flag:"port" transforms:"trim"`
+	}
+	envflagparser.RegisterTransform("nodots", func(value string) (string, error) {
+		return strings.ReplaceAll(value, ".", ""), nil
+	})
+	defer envflagparser.RegisterTransform("nodots", nil)
+
+	setArgs(t, "-port", " 8080 ")
+	t.Setenv("TRANSFORM_LEVEL", "  DEBUG ")
+	t.Setenv("TRANSFORM_REGION", " eu-west ")
+	t.Setenv("TRANSFORM_BASE", "/srv/v1.2")
+	t.Setenv("TRANSFORM_PATH", "${TRANSFORM_BASE}/data")
+
+	var config TransformConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Level != "debug" {
+		t.Errorf("Expected Level: %q, Got: %q", "debug", config.Level)
+	}
+	if config.Region != "EU-WEST" {
+		t.Errorf("Expected Region: %q, Got: %q", "EU-WEST", config.Region)
+	}
+	if config.Path != "/srv/v12/data" {
+		t.Errorf("Expected Path: %q, Got: %q", "/srv/v12/data", config.Path)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected Port: %d, Got: %d", 8080, config.Port)
+	}
+}
+
+func TestUnknownTransform(t *testing.T) {
+	setArgs(t)
+	t.Setenv("TRANSFORM_LEVEL", "debug")
+
+	var config struct {
+		Level string `env:"TRANSFORM_LEVEL" transforms:"trim,title"`
+	}
+	if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), "unknown transform title") {
+		t.Errorf("Expected unknown transform error, Got: %v", err)
+	}
+}
+
+func TestFileEnvSuffix(t *testing.T) {
+	defer func(old string) { envflagparser.FileEnvSuffix = old }(envflagparser.FileEnvSuffix)
+	envflagparser.FileEnvSuffix = "_FILE"
+
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatalf("Error writing secret file: %v", err)
+	}
+
+	type FileEnvConfig struct {
+		Password string `env:"FILEENV_PASSWORD"`
+		User     string `env:"FILEENV_USER" default:"admin"`
+	}
+
+	setArgs(t)
+	t.Setenv("FILEENV_PASSWORD_FILE", path)
+	var config FileEnvConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := (FileEnvConfig{Password: "s3cret", User: "admin"}); config != expected {
+		t.Errorf("Expected config: %+v, Got: %+v", expected, config)
+	}
+
+	// The variable itself takes precedence over its file.
+	t.Setenv("FILEENV_PASSWORD", "direct")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Password != "direct" {
+		t.Errorf("Expected Password: %s, Got: %s", "direct", config.Password)
+	}
+
+	expected := []string{"FILEENV_PASSWORD", "FILEENV_PASSWORD_FILE", "FILEENV_USER", "FILEENV_USER_FILE"}
+	if keys := envflagparser.EnvKeys(&config); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys: %v, Got: %v", expected, keys)
+	}
+
+	os.Unsetenv("FILEENV_PASSWORD")
+	t.Setenv("FILEENV_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
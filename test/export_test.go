@@ -0,0 +1,45 @@
+package envflagparser_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type DatabaseConfig struct {
+	Host string
+	Port int
+}
+
+type ExportConfig struct {
+	Name     string        `env:"EXPORT_NAME" default:"app"`
+	Timeout  time.Duration `env:"EXPORT_TIMEOUT" default:"5s"`
+	Started  time.Time
+	Database DatabaseConfig
+	internal string
+}
+
+func TestToMap(t *testing.T) {
+	setArgs(t)
+	t.Setenv("EXPORT_NAME", "service")
+
+	config := ExportConfig{Database: DatabaseConfig{Host: "db", Port: 5432}, internal: "hidden"}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"Name":    "service",
+		"Timeout": 5 * time.Second,
+		"Started": time.Time{},
+		"Database": map[string]interface{}{
+			"Host": "db",
+			"Port": 5432,
+		},
+	}
+	if values := envflagparser.ToMap(&config); !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected map: %v, Got: %v", expected, values)
+	}
+}
@@ -0,0 +1,173 @@
+package envflagparser_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type NameConfig struct {
+	Name string `env:"FLAGTEST_NAME" flag:"name" default:"MyApp"`
+}
+
+func TestUnquoteFlagValues(t *testing.T) {
+	envflagparser.UnquoteFlagValues = true
+	defer func() { envflagparser.UnquoteFlagValues = false }()
+	setArgs(t, "-name", `"App"`)
+
+	var config NameConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Name != "App" {
+		t.Errorf("Expected Name: %s, Got: %s", "App", config.Name)
+	}
+}
+
+func TestQuotedFlagValueKeptByDefault(t *testing.T) {
+	setArgs(t, "-name", `"App"`)
+
+	var config NameConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Name != `"App"` {
+		t.Errorf("Expected Name: %s, Got: %s", `"App"`, config.Name)
+	}
+}
+
+type FlagValuesConfig struct {
+	Port    int           `env:"FLAGVALUES_PORT" flag:"port" default:"8080"`
+	Timeout time.Duration `env:"FLAGVALUES_TIMEOUT" flag:"timeout" default:"10s"`
+	Name    string        `env:"FLAGVALUES_NAME" flag:"name"`
+}
+
+func TestRegisterAndParse(t *testing.T) {
+	setArgs(t, "-port", "9090")
+	t.Setenv("FLAGVALUES_PORT", "7070")
+
+	var config FlagValuesConfig
+	result, err := envflagparser.RegisterAndParse(&config)
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	// The environment variable is prioritised, the flag value is still reported.
+	if config.Port != 7070 {
+		t.Errorf("Expected Port: %d, Got: %d", 7070, config.Port)
+	}
+	expected := map[string]interface{}{
+		"port":    9090,
+		"timeout": 10 * time.Second,
+		"name":    "",
+	}
+	if !reflect.DeepEqual(result.FlagValues, expected) {
+		t.Errorf("Expected FlagValues: %v, Got: %v", expected, result.FlagValues)
+	}
+	if !result.SetFlags["port"] || result.SetFlags["timeout"] {
+		t.Errorf("Expected only port to be set, Got: %v", result.SetFlags)
+	}
+}
+
+func TestInterspersedFlags(t *testing.T) {
+	type CLIConfig struct {
+		Verbose bool   `flag:"verbose"`
+		Port    int    `flag:"port"`
+		Name    string `flag:"name"`
+	}
+
+	defer func(old bool) { envflagparser.InterspersedFlags = old }(envflagparser.InterspersedFlags)
+	envflagparser.InterspersedFlags = true
+
+	setArgs(t, "file.txt", "-verbose", "-port", "9090", "other", "--name=app", "--", "-literal", "-port")
+	var config CLIConfig
+	result, err := envflagparser.RegisterAndParse(&config)
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if !config.Verbose || config.Port != 9090 || config.Name != "app" {
+		t.Errorf("Expected Verbose, Port and Name: true 9090 app, Got: %v %d %s", config.Verbose, config.Port, config.Name)
+	}
+	if expected := []string{"file.txt", "other", "-literal", "-port"}; !reflect.DeepEqual(result.Args, expected) {
+		t.Errorf("Expected positional args: %v, Got: %v", expected, result.Args)
+	}
+
+	// Without InterspersedFlags, parsing stops at the first positional argument.
+	envflagparser.InterspersedFlags = false
+	setArgs(t, "file.txt", "-verbose")
+	config = CLIConfig{}
+	result, err = envflagparser.RegisterAndParse(&config)
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Verbose {
+		t.Errorf("Expected Verbose: false, Got: %v", config.Verbose)
+	}
+	if expected := []string{"file.txt", "-verbose"}; !reflect.DeepEqual(result.Args, expected) {
+		t.Errorf("Expected positional args: %v, Got: %v", expected, result.Args)
+	}
+}
+
+func TestDuplicateFlagName(t *testing.T) {
+	setArgs(t, "-a", "5")
+	var config struct {
+		A int `flag:"a"`
+		B int `flag:"a"`
+	}
+	if err := envflagparser.ParseConfig(&config); err == nil || err.Error() != "flag redefined: a" {
+		t.Errorf("Expected error: %s, Got: %v", "flag redefined: a", err)
+	}
+}
+
+func TestSetFlagsNotCarriedOver(t *testing.T) {
+	type RepeatConfig struct {
+		Port int `env:"REPEAT_PORT" flag:"port" default:"8080"`
+	}
+
+	setArgs(t, "-port", "9090")
+	var config RepeatConfig
+	if _, err := envflagparser.RegisterAndParse(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+
+	// The command line changes without resetting the flags of the previous parse.
+	os.Args = os.Args[:1]
+	config = RepeatConfig{}
+	result, err := envflagparser.RegisterAndParse(&config)
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Port != 8080 || result.SetFlags["port"] {
+		t.Errorf("Expected Port: %d and the flag unset, Got: %d, %v", 8080, config.Port, result.SetFlags)
+	}
+}
+
+func TestUnsupportedFlagType(t *testing.T) {
+	setArgs(t)
+	var config struct {
+		Signal complex128 `env:"UNSUPPORTED_SIGNAL" flag:"signal"`
+	}
+	expected := "unsupported type complex128 of flag signal"
+	if err := envflagparser.ParseConfig(&config); err == nil || err.Error() != expected {
+		t.Errorf("Expected error: %s, Got: %v", expected, err)
+	}
+	if _, err := envflagparser.FlagSet(&config); err == nil || err.Error() != expected {
+		t.Errorf("Expected error: %s, Got: %v", expected, err)
+	}
+
+	// Without a flag, a value of the environment is rejected when it is set.
+	var envConfig struct {
+		Signal complex128 `env:"UNSUPPORTED_SIGNAL"`
+	}
+	t.Setenv("UNSUPPORTED_SIGNAL", "1+2i")
+	if err := envflagparser.ParseConfig(&envConfig); err == nil || !strings.Contains(err.Error(), "unsupported field type complex128") {
+		t.Errorf("Expected unsupported field type error, Got: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package envflagparser_test
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type SubcommandConfig struct {
+	Port    int           `env:"SUB_PORT" flag:"port" default:"8080"`
+	Name    string        `env:"SUB_NAME" flag:"name"`
+	Timeout time.Duration `env:"SUB_TIMEOUT" flag:"timeout"`
+	Debug   bool          `env:"SUB_DEBUG" default:"true"`
+}
+
+func TestBindFromFlagSet(t *testing.T) {
+	t.Setenv("SUB_NAME", "from-env")
+	t.Setenv("SUB_TIMEOUT", "3s")
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.Int("port", 80, "")
+	fs.String("name", "", "")
+	fs.Duration("timeout", time.Second, "")
+	if err := fs.Parse([]string{"-port", "9090"}); err != nil {
+		t.Fatalf("Error parsing flags: %v", err)
+	}
+
+	var config SubcommandConfig
+	if err := envflagparser.BindFromFlagSet(&config, fs); err != nil {
+		t.Fatalf("Error binding config: %v", err)
+	}
+
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+	if config.Name != "from-env" {
+		t.Errorf("Expected Name: %s, Got: %s", "from-env", config.Name)
+	}
+	if config.Timeout != 3*time.Second {
+		t.Errorf("Expected Timeout: %s, Got: %s", 3*time.Second, config.Timeout)
+	}
+	if !config.Debug {
+		t.Errorf("Expected Debug: %t, Got: %t", true, config.Debug)
+	}
+}
+
+func TestBindFromFlagSetFlagDefault(t *testing.T) {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.Int("port", 80, "")
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("Error parsing flags: %v", err)
+	}
+
+	var config SubcommandConfig
+	if err := envflagparser.BindFromFlagSet(&config, fs); err != nil {
+		t.Fatalf("Error binding config: %v", err)
+	}
+
+	if config.Port != 80 {
+		t.Errorf("Expected Port: %d, Got: %d", 80, config.Port)
+	}
+}
+
+func TestBindFromFlagSetUnsetPointer(t *testing.T) {
+	type PointerFlagConfig struct {
+		Name    *string `env:"BIND_PTR_NAME" flag:"name"`
+		Port    *int    `env:"BIND_PTR_PORT" flag:"port"`
+		Retries *int    `env:"BIND_PTR_RETRIES" flag:"retries" default:"3"`
+	}
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.String("name", "", "")
+	fs.Int("port", 0, "")
+	fs.Int("retries", 5, "")
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("Error parsing flags: %v", err)
+	}
+
+	var config PointerFlagConfig
+	if err := envflagparser.BindFromFlagSet(&config, fs); err != nil {
+		t.Fatalf("Error binding config: %v", err)
+	}
+
+	// Unset flags with zero defaults leave pointer fields nil, a non-zero flag default overrides the default tag.
+	if config.Name != nil {
+		t.Errorf("Expected Name: nil, Got: %q", *config.Name)
+	}
+	if config.Port != nil {
+		t.Errorf("Expected Port: nil, Got: %d", *config.Port)
+	}
+	if config.Retries == nil || *config.Retries != 5 {
+		t.Errorf("Expected Retries: 5, Got: %v", config.Retries)
+	}
+}
+
+func TestBindFromFlagSetDefaultPrecedence(t *testing.T) {
+	type PrecedenceFlagConfig struct {
+		Port    int  `env:"BIND_PREC_PORT" flag:"port" default:"8080"`
+		PortPtr *int `env:"BIND_PREC_PORT_PTR" flag:"port-ptr" default:"8080"`
+		Workers int  `env:"BIND_PREC_WORKERS" flag:"workers" default:"4"`
+		Retries *int `env:"BIND_PREC_RETRIES" flag:"retries" default:"4"`
+	}
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.Int("port", 0, "")
+	fs.Int("port-ptr", 0, "")
+	fs.Int("workers", 8, "")
+	fs.Int("retries", 8, "")
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("Error parsing flags: %v", err)
+	}
+
+	var config PrecedenceFlagConfig
+	if err := envflagparser.BindFromFlagSet(&config, fs); err != nil {
+		t.Fatalf("Error binding config: %v", err)
+	}
+
+	// Pointer and non-pointer fields follow the same precedence: zero flag defaults keep the default tag.
+	if config.Port != 8080 || config.PortPtr == nil || *config.PortPtr != 8080 {
+		t.Errorf("Expected Port and PortPtr: 8080, Got: %d, %v", config.Port, config.PortPtr)
+	}
+	if config.Workers != 8 || config.Retries == nil || *config.Retries != 8 {
+		t.Errorf("Expected Workers and Retries: 8, Got: %d, %v", config.Workers, config.Retries)
+	}
+}
+
+type CompletionConfig struct {
+	Host  string `env:"COMP_HOST" flag:"host" default:"localhost" usage:"Server host"`
+	Port  int    `env:"COMP_PORT" flag:"port" default:"8080" usage:"Server port"`
+	Token string `env:"COMP_TOKEN"`
+}
+
+func TestFlagSet(t *testing.T) {
+	fs, err := envflagparser.FlagSet(&CompletionConfig{})
+	if err != nil {
+		t.Fatalf("Error building flag set: %v", err)
+	}
+
+	expected := map[string][2]string{
+		"host": {"localhost", "Server host"},
+		"port": {"8080", "Server port"},
+	}
+	count := 0
+	fs.VisitAll(func(f *flag.Flag) {
+		count++
+		want, ok := expected[f.Name]
+		if !ok {
+			t.Errorf("Unexpected flag: %s", f.Name)
+			return
+		}
+		if f.DefValue != want[0] || f.Usage != want[1] {
+			t.Errorf("Expected flag %s with default %q and usage %q, Got: %q and %q", f.Name, want[0], want[1], f.DefValue, f.Usage)
+		}
+	})
+	if count != len(expected) {
+		t.Errorf("Expected %d flags, Got: %d", len(expected), count)
+	}
+	if fs.Parsed() {
+		t.Error("Expected flag set not to be parsed")
+	}
+}
+
+func TestFlagSetDuplicateFlag(t *testing.T) {
+	var config struct {
+		A string `flag:"name"`
+		B string `flag:"name"`
+	}
+	if _, err := envflagparser.FlagSet(&config); err == nil {
+		t.Error("Expected error for duplicate flag")
+	}
+}
+
+type ShellCompletionConfig struct {
+	Port    int    `flag:"port" usage:"Server port [1-65535]"`
+	Name    string `flag:"name" usage:"App name, e.g. 'api'"`
+	Verbose bool   `flag:"verbose" usage:"Verbose logs"`
+	Debug   bool   `flag:"debug-internals" hiddenflag:"true"`
+}
+
+func TestBashCompletion(t *testing.T) {
+	setArgs(t)
+
+	script := envflagparser.BashCompletion(&ShellCompletionConfig{})
+	expected := `# bash completion for envflagparser.test
+_envflagparser_test_completions() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	COMPREPLY=($(compgen -W "-name -port -verbose" -- "$cur"))
+}
+complete -F _envflagparser_test_completions envflagparser.test
+`
+	if script != expected {
+		t.Errorf("Expected script:\n%s\nGot:\n%s", expected, script)
+	}
+}
+
+func TestZshCompletion(t *testing.T) {
+	setArgs(t)
+
+	script := envflagparser.ZshCompletion(&ShellCompletionConfig{})
+	for _, flagName := range []string{"-name", "-port", "-verbose"} {
+		if !strings.Contains(script, "'"+flagName+"[") {
+			t.Errorf("Expected flag %s in script:\n%s", flagName, script)
+		}
+	}
+	if strings.Contains(script, "debug-internals") {
+		t.Errorf("Expected hidden flag to be omitted from script:\n%s", script)
+	}
+	expected := `#compdef envflagparser.test
+
+_arguments \
+	'-name[App name, e.g. '\''api'\'']:name:' \
+	'-port[Server port \[1-65535\]]:port:' \
+	'-verbose[Verbose logs]'
+`
+	if script != expected {
+		t.Errorf("Expected script:\n%s\nGot:\n%s", expected, script)
+	}
+}
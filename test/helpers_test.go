@@ -0,0 +1,18 @@
+package envflagparser_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+// setArgs replaces the command line seen by ParseConfig for the duration of the test.
+func setArgs(t *testing.T, args ...string) {
+	t.Helper()
+	oldArgs, oldCommandLine := os.Args, flag.CommandLine
+	os.Args = append([]string{"envflagparser.test"}, args...)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.PanicOnError)
+	t.Cleanup(func() {
+		os.Args, flag.CommandLine = oldArgs, oldCommandLine
+	})
+}
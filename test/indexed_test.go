@@ -0,0 +1,62 @@
+package envflagparser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type Upstream struct {
+	Host   string `env:"HOST"`
+	Port   int    `env:"PORT" default:"80"`
+	Weight int
+}
+
+type IndexedConfig struct {
+	Name      string     `env:"INDEXED_NAME"`
+	Upstreams []Upstream `indexedstruct:"UPSTREAM"`
+}
+
+func TestIndexedStruct(t *testing.T) {
+	setArgs(t)
+	t.Setenv("UPSTREAM_1_HOST", "a.example.com")
+	t.Setenv("UPSTREAM_1_PORT", "8080")
+	t.Setenv("UPSTREAM_2_HOST", "b.example.com")
+	t.Setenv("UPSTREAM_2_WEIGHT", "3")
+	t.Setenv("UPSTREAM_4_HOST", "skipped.example.com")
+
+	var config IndexedConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	expected := []Upstream{
+		{Host: "a.example.com", Port: 8080},
+		{Host: "b.example.com", Port: 80, Weight: 3},
+	}
+	if !reflect.DeepEqual(config.Upstreams, expected) {
+		t.Errorf("Expected Upstreams: %+v, Got: %+v", expected, config.Upstreams)
+	}
+}
+
+func TestIndexedStructInvalidValue(t *testing.T) {
+	setArgs(t)
+	t.Setenv("UPSTREAM_1_PORT", "http")
+
+	var config IndexedConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for invalid element value")
+	}
+}
+
+func TestIndexedStructWrongType(t *testing.T) {
+	setArgs(t)
+
+	var config struct {
+		Upstreams []string `indexedstruct:"UPSTREAM"`
+	}
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for indexed struct field that is not a slice of structs")
+	}
+}
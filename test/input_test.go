@@ -0,0 +1,55 @@
+package envflagparser_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type InputConfig struct {
+	Input io.Reader `env:"INPUT_FILE" flag:"input" stdinaware:"true"`
+	Path  string    `env:"INPUT_PATH" stdinaware:"true"`
+}
+
+func TestStdinAware(t *testing.T) {
+	setArgs(t, "-input", "-")
+	t.Setenv("INPUT_PATH", "-")
+
+	var config InputConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Input != os.Stdin {
+		t.Errorf("Expected Input: %v, Got: %v", os.Stdin, config.Input)
+	}
+	if config.Path != envflagparser.StdinPath {
+		t.Errorf("Expected Path: %s, Got: %s", envflagparser.StdinPath, config.Path)
+	}
+}
+
+func TestStdinAwareFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte("content"), 0o600); err != nil {
+		t.Fatalf("Error writing input file: %v", err)
+	}
+	setArgs(t, "-input", path)
+
+	var config InputConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Input == os.Stdin {
+		t.Fatal("Expected Input not to be stdin")
+	}
+	content, err := io.ReadAll(config.Input)
+	if err != nil {
+		t.Fatalf("Error reading input: %v", err)
+	}
+	if string(content) != "content" {
+		t.Errorf("Expected content: %s, Got: %s", "content", content)
+	}
+	config.Input.(io.Closer).Close()
+}
@@ -0,0 +1,208 @@
+package envflagparser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type ServiceConfig struct {
+	Port int    `json:"port"`
+	Host string `json:"host"`
+}
+
+type MapConfig struct {
+	Services map[string]ServiceConfig `env:"MAP_SERVICES"`
+	Limits   map[string]int           `env:"MAP_LIMITS" flag:"limits"`
+	Timeouts map[string]time.Duration `env:"MAP_TIMEOUTS"`
+}
+
+func TestStructValueMapFromJSON(t *testing.T) {
+	setArgs(t)
+	t.Setenv("MAP_SERVICES", `{"svc1":{"port":80},"svc2":{"port":443,"host":"b"}}`)
+
+	var config MapConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	expected := map[string]ServiceConfig{"svc1": {Port: 80}, "svc2": {Port: 443, Host: "b"}}
+	if !reflect.DeepEqual(config.Services, expected) {
+		t.Errorf("Expected Services: %v, Got: %v", expected, config.Services)
+	}
+}
+
+func TestScalarMap(t *testing.T) {
+	setArgs(t, "-limits", "cpu=2,memory=512")
+	t.Setenv("MAP_TIMEOUTS", "read=5s,write=10s")
+
+	var config MapConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := map[string]int{"cpu": 2, "memory": 512}; !reflect.DeepEqual(config.Limits, expected) {
+		t.Errorf("Expected Limits: %v, Got: %v", expected, config.Limits)
+	}
+	if expected := map[string]time.Duration{"read": 5 * time.Second, "write": 10 * time.Second}; !reflect.DeepEqual(config.Timeouts, expected) {
+		t.Errorf("Expected Timeouts: %v, Got: %v", expected, config.Timeouts)
+	}
+}
+
+func TestMapInvalidJSON(t *testing.T) {
+	setArgs(t)
+	t.Setenv("MAP_SERVICES", `{"svc1":`)
+
+	var config MapConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestMapEscapedSeparators(t *testing.T) {
+	setArgs(t)
+	t.Setenv("MAP_URLS", `home=http://a?x=1\,y=2,about=http://b,a\=b=c,path=C:\dir\`)
+
+	var config struct {
+		URLs map[string]string `env:"MAP_URLS"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	expected := map[string]string{
+		"home":  "http://a?x=1,y=2",
+		"about": "http://b",
+		"a=b":   "c",
+		"path":  `C:\dir\`,
+	}
+	if !reflect.DeepEqual(config.URLs, expected) {
+		t.Errorf("Expected URLs: %v, Got: %v", expected, config.URLs)
+	}
+}
+
+type Middleware struct {
+	Key   string
+	Value int
+}
+
+func TestOrderedMap(t *testing.T) {
+	setArgs(t)
+	t.Setenv("MAP_WEIGHTS", "c=3,a=1,b=2")
+	t.Setenv("MAP_MIDDLEWARE", "auth=10,log=5,gzip=1")
+
+	var config struct {
+		Weights    envflagparser.OrderedMap[int] `env:"MAP_WEIGHTS"`
+		Middleware []Middleware                  `env:"MAP_MIDDLEWARE"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if keys := config.Weights.Keys(); !reflect.DeepEqual(keys, []string{"c", "a", "b"}) {
+		t.Errorf("Expected keys in insertion order: %v, Got: %v", []string{"c", "a", "b"}, keys)
+	}
+	if value, ok := config.Weights.Get("a"); !ok || value != 1 {
+		t.Errorf("Expected a: %d, Got: %d", 1, value)
+	}
+	expected := []Middleware{{"auth", 10}, {"log", 5}, {"gzip", 1}}
+	if !reflect.DeepEqual(config.Middleware, expected) {
+		t.Errorf("Expected Middleware: %v, Got: %v", expected, config.Middleware)
+	}
+
+	data, err := envflagparser.MarshalEnv(&config)
+	if err != nil {
+		t.Fatalf("Error marshaling config: %v", err)
+	}
+	if !strings.Contains(string(data), "MAP_WEIGHTS=c=3,a=1,b=2\n") {
+		t.Errorf("Expected ordered map to marshal in order, Got: %s", data)
+	}
+}
+
+func TestOrderedMapInvalidValue(t *testing.T) {
+	setArgs(t)
+	t.Setenv("MAP_WEIGHTS", "a=1,b=two")
+
+	var config struct {
+		Weights envflagparser.OrderedMap[int] `env:"MAP_WEIGHTS"`
+	}
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for invalid ordered map value")
+	}
+}
+
+func TestMapEmptyValue(t *testing.T) {
+	setArgs(t)
+	t.Setenv("MAP_EMPTY", "a=,b=1")
+
+	var config struct {
+		M       map[string]string `env:"MAP_EMPTY"`
+		Default map[string]string `env:"MAP_EMPTY" mapdefault:"x"`
+	}
+	defer func(old bool) { envflagparser.AllowSharedEnv = old }(envflagparser.AllowSharedEnv)
+	envflagparser.AllowSharedEnv = true
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	expected := map[string]string{"a": "", "b": "1"}
+	if !reflect.DeepEqual(config.M, expected) {
+		t.Errorf("Expected M: %v, Got: %v", expected, config.M)
+	}
+	// An explicit empty value is kept instead of the mapdefault.
+	if !reflect.DeepEqual(config.Default, expected) {
+		t.Errorf("Expected Default: %v, Got: %v", expected, config.Default)
+	}
+}
+
+func TestDurationMapDefault(t *testing.T) {
+	type CacheConfig struct {
+		TTLs map[string]time.Duration `env:"CACHE_TTLS" mapdefault:"1m"`
+	}
+
+	setArgs(t)
+	t.Setenv("CACHE_TTLS", "a=30s,b=5m,c")
+	var config CacheConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	expected := map[string]time.Duration{"a": 30 * time.Second, "b": 5 * time.Minute, "c": time.Minute}
+	if !reflect.DeepEqual(config.TTLs, expected) {
+		t.Errorf("Expected TTLs: %v, Got: %v", expected, config.TTLs)
+	}
+
+	t.Setenv("CACHE_TTLS", "a=30s,b=5x")
+	if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), `key "b"`) {
+		t.Errorf("Expected error naming key b, Got: %v", err)
+	}
+}
+
+func TestMapOfSlices(t *testing.T) {
+	type RouteConfig struct {
+		Routes map[string][]int `env:"ROUTES"`
+	}
+
+	setArgs(t)
+	t.Setenv("ROUTES", "a=1,a=2,b=3")
+	var config RouteConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	expected := map[string][]int{"a": {1, 2}, "b": {3}}
+	if !reflect.DeepEqual(config.Routes, expected) {
+		t.Errorf("Expected Routes: %v, Got: %v", expected, config.Routes)
+	}
+
+	t.Setenv("ROUTES", `{"a":[4,5]}`)
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := map[string][]int{"a": {4, 5}}; !reflect.DeepEqual(config.Routes, expected) {
+		t.Errorf("Expected Routes: %v, Got: %v", expected, config.Routes)
+	}
+
+	t.Setenv("ROUTES", "a=1,b,c=3")
+	if err := envflagparser.ParseConfig(&RouteConfig{}); err == nil || !strings.Contains(err.Error(), `entry 1 "b": expected key=value`) {
+		t.Errorf("Expected malformed entry error, Got: %v", err)
+	}
+}
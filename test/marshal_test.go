@@ -0,0 +1,90 @@
+package envflagparser_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type MarshalConfig struct {
+	Debug   bool              `env:"MARSHAL_DEBUG" truevals:"yes,on" falsevals:"no,off"`
+	Verbose bool              `env:"MARSHAL_VERBOSE" truevals:"on" falsevals:"off"`
+	Plain   bool              `env:"MARSHAL_PLAIN"`
+	Timeout time.Duration     `env:"MARSHAL_TIMEOUT"`
+	Hosts   []string          `env:"MARSHAL_HOSTS"`
+	Labels  map[string]string `env:"MARSHAL_LABELS"`
+	Name    string            `env:"MARSHAL_NAME"`
+	Port    *int              `env:"MARSHAL_PORT"`
+	Local   string            `flag:"local"`
+}
+
+func TestBoolVocabulary(t *testing.T) {
+	setArgs(t)
+	t.Setenv("MARSHAL_DEBUG", "Yes")
+	t.Setenv("MARSHAL_VERBOSE", "off")
+	t.Setenv("MARSHAL_PLAIN", "true")
+
+	var config MarshalConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if !config.Debug || config.Verbose || !config.Plain {
+		t.Errorf("Expected Debug, Verbose, Plain: true, false, true, Got: %t, %t, %t", config.Debug, config.Verbose, config.Plain)
+	}
+}
+
+func TestMarshalEnv(t *testing.T) {
+	config := MarshalConfig{
+		Debug:   true,
+		Timeout: 5 * time.Second,
+		Hosts:   []string{"a", "b"},
+		Labels:  map[string]string{"team": "core", "url": "http://a?x=1,y=2"},
+		Name:    "my app",
+	}
+
+	data, err := envflagparser.MarshalEnv(&config)
+	if err != nil {
+		t.Fatalf("Error marshaling config: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"MARSHAL_DEBUG=yes",
+		"MARSHAL_VERBOSE=off",
+		"MARSHAL_PLAIN=false",
+		"MARSHAL_TIMEOUT=5s",
+		"MARSHAL_HOSTS=a,b",
+		`MARSHAL_LABELS=team=core,url=http://a?x=1\,y=2`,
+		`MARSHAL_NAME="my app"`,
+	}, "\n") + "\n"
+	if string(data) != expected {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expected, data)
+	}
+}
+
+func TestMarshalEnvRoundTrip(t *testing.T) {
+	port := 8080
+	original := MarshalConfig{
+		Debug:  true,
+		Hosts:  []string{"a", "b"},
+		Labels: map[string]string{"url": "http://a?x=1,y=2"},
+		Port:   &port,
+	}
+	data, err := envflagparser.MarshalEnv(&original)
+	if err != nil {
+		t.Fatalf("Error marshaling config: %v", err)
+	}
+
+	setArgs(t)
+	setEnvFile(t, string(data))
+	var parsed MarshalConfig
+	if err := envflagparser.ParseConfig(&parsed); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if !parsed.Debug || parsed.Labels["url"] != "http://a?x=1,y=2" || parsed.Port == nil || *parsed.Port != 8080 {
+		t.Errorf("Expected config to round-trip: %+v, Got: %+v", original, parsed)
+	}
+}
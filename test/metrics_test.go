@@ -0,0 +1,73 @@
+package envflagparser_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+// recordingSink is a MetricsSink counting the observed fields and errors by kind.
+type recordingSink struct {
+	fields map[reflect.Kind]int
+	errors map[reflect.Kind]int
+}
+
+func (s *recordingSink) ObserveField(kind reflect.Kind, dur time.Duration) {
+	s.fields[kind]++
+}
+
+func (s *recordingSink) ObserveError(kind reflect.Kind, err error) {
+	s.errors[kind]++
+}
+
+func recordMetrics(t *testing.T) *recordingSink {
+	t.Helper()
+	sink := &recordingSink{fields: make(map[reflect.Kind]int), errors: make(map[reflect.Kind]int)}
+	oldMetrics := envflagparser.Metrics
+	envflagparser.Metrics = sink
+	t.Cleanup(func() { envflagparser.Metrics = oldMetrics })
+	return sink
+}
+
+type MetricsConfig struct {
+	Host    string `env:"METRICS_HOST" default:"localhost"`
+	Port    int    `env:"METRICS_PORT" flag:"port"`
+	Workers int    `env:"METRICS_WORKERS"`
+	Debug   bool   `env:"METRICS_DEBUG"`
+}
+
+func TestMetricsFieldCounts(t *testing.T) {
+	sink := recordMetrics(t)
+	setArgs(t, "-port", "8080")
+	t.Setenv("METRICS_WORKERS", "4")
+
+	var config MetricsConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	expected := map[reflect.Kind]int{reflect.String: 1, reflect.Int: 2}
+	if !reflect.DeepEqual(sink.fields, expected) {
+		t.Errorf("Expected field counts: %v, Got: %v", expected, sink.fields)
+	}
+	if len(sink.errors) != 0 {
+		t.Errorf("Expected no errors, Got: %v", sink.errors)
+	}
+}
+
+func TestMetricsErrors(t *testing.T) {
+	sink := recordMetrics(t)
+	setArgs(t)
+	t.Setenv("METRICS_DEBUG", "maybe")
+
+	var config MetricsConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Fatal("Expected error for invalid bool")
+	}
+
+	if sink.errors[reflect.Bool] != 1 {
+		t.Errorf("Expected 1 bool error, Got: %v", sink.errors)
+	}
+}
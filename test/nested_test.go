@@ -0,0 +1,121 @@
+package envflagparser_test
+
+import (
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type PrefixedDatabaseConfig struct {
+	Host     string `env:"HOST" default:"localhost"`
+	MaxConns int
+	Region   string `env:"/GLOBAL_REGION"`
+	Ignored  string `env:"-"`
+}
+
+type NestedConfig struct {
+	Port     int                    `env:"PORT" flag:"port" default:"8080"`
+	Database PrefixedDatabaseConfig `prefix:"DB_"`
+}
+
+func TestNestedPrefix(t *testing.T) {
+	setArgs(t)
+	t.Setenv("DB_HOST", "db.example.com")
+	t.Setenv("DB_MAX_CONNS", "20")
+	t.Setenv("GLOBAL_REGION", "eu")
+	t.Setenv("DB_REGION", "us")
+	t.Setenv("DB_IGNORED", "set")
+
+	var config NestedConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Port != 8080 {
+		t.Errorf("Expected Port: %d, Got: %d", 8080, config.Port)
+	}
+	if config.Database.Host != "db.example.com" {
+		t.Errorf("Expected Database.Host: %s, Got: %s", "db.example.com", config.Database.Host)
+	}
+	if config.Database.MaxConns != 20 {
+		t.Errorf("Expected Database.MaxConns: %d, Got: %d", 20, config.Database.MaxConns)
+	}
+	if config.Database.Region != "eu" {
+		t.Errorf("Expected Database.Region: %s, Got: %s", "eu", config.Database.Region)
+	}
+	if config.Database.Ignored != "" {
+		t.Errorf("Expected Database.Ignored to be empty, Got: %s", config.Database.Ignored)
+	}
+}
+
+func TestNestedDefault(t *testing.T) {
+	setArgs(t)
+
+	var config NestedConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Database.Host != "localhost" {
+		t.Errorf("Expected Database.Host: %s, Got: %s", "localhost", config.Database.Host)
+	}
+}
+
+func TestNestedAmbiguous(t *testing.T) {
+	setArgs(t)
+
+	var prefixOnScalar struct {
+		Host string `env:"HOST" prefix:"DB_"`
+	}
+	if err := envflagparser.ParseConfig(&prefixOnScalar); err == nil {
+		t.Error("Expected error for prefix on a field that is not a nested struct")
+	}
+
+	var emptyAbsolute struct {
+		Database struct {
+			Host string `env:"/"`
+		} `prefix:"DB_"`
+	}
+	if err := envflagparser.ParseConfig(&emptyAbsolute); err == nil {
+		t.Error("Expected error for empty absolute env tag")
+	}
+}
+
+func TestEnvKeyTemplate(t *testing.T) {
+	envflagparser.EnvKeyTemplate = `SVC_{{.Prefix}}{{if .Env}}{{.Env}}{{else}}{{.Field}}{{end}}_VALUE`
+	defer func() { envflagparser.EnvKeyTemplate = "" }()
+	setArgs(t)
+	t.Setenv("SVC_PORT_VALUE", "9090")
+	t.Setenv("SVC_DB_HOST_VALUE", "db.example.com")
+	t.Setenv("SVC_DB_MAX_CONNS_VALUE", "20")
+	t.Setenv("GLOBAL_REGION", "eu")
+
+	var config NestedConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+	if config.Database.Host != "db.example.com" {
+		t.Errorf("Expected Database.Host: %s, Got: %s", "db.example.com", config.Database.Host)
+	}
+	if config.Database.MaxConns != 20 {
+		t.Errorf("Expected Database.MaxConns: %d, Got: %d", 20, config.Database.MaxConns)
+	}
+	if config.Database.Region != "eu" {
+		t.Errorf("Expected absolute Database.Region: %s, Got: %s", "eu", config.Database.Region)
+	}
+}
+
+func TestInvalidEnvKeyTemplate(t *testing.T) {
+	envflagparser.EnvKeyTemplate = `SVC_{{.Field`
+	defer func() { envflagparser.EnvKeyTemplate = "" }()
+	setArgs(t)
+
+	var config NestedConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for invalid env key template")
+	}
+}
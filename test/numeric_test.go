@@ -0,0 +1,486 @@
+package envflagparser_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type Port uint16
+
+type NumericConfig struct {
+	Port   Port    `env:"NUMERIC_PORT" flag:"port" default:"8080"`
+	Offset int8    `env:"NUMERIC_OFFSET"`
+	Ratio  float32 `env:"NUMERIC_RATIO"`
+}
+
+func TestNamedNumericTypeFromEnv(t *testing.T) {
+	setArgs(t)
+	t.Setenv("NUMERIC_PORT", "9090")
+	t.Setenv("NUMERIC_OFFSET", "-5")
+	t.Setenv("NUMERIC_RATIO", "0.5")
+
+	var config NumericConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+	if config.Offset != -5 {
+		t.Errorf("Expected Offset: %d, Got: %d", -5, config.Offset)
+	}
+	if config.Ratio != 0.5 {
+		t.Errorf("Expected Ratio: %f, Got: %f", 0.5, config.Ratio)
+	}
+}
+
+func TestNamedNumericTypeFromFlag(t *testing.T) {
+	setArgs(t, "-port", "443")
+
+	var config NumericConfig
+	result, err := envflagparser.RegisterAndParse(&config)
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Port != 443 {
+		t.Errorf("Expected Port: %d, Got: %d", 443, config.Port)
+	}
+	if port, ok := result.FlagValues["port"].(Port); !ok || port != 443 {
+		t.Errorf("Expected flag value of type Port: %d, Got: %#v", 443, result.FlagValues["port"])
+	}
+}
+
+func TestNamedNumericTypeOverflow(t *testing.T) {
+	setArgs(t)
+	t.Setenv("NUMERIC_PORT", "70000")
+
+	var config NumericConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for value overflowing uint16")
+	}
+}
+
+func TestIntegerRejectsFloat(t *testing.T) {
+	for _, value := range []string{"3.0", "1e2", "2E3"} {
+		setArgs(t)
+		t.Setenv("NUMERIC_OFFSET", value)
+
+		var config NumericConfig
+		err := envflagparser.ParseConfig(&config)
+		if err == nil {
+			t.Errorf("Expected error for float value %s on an integer field", value)
+			continue
+		}
+		if !strings.Contains(err.Error(), "float value "+value+" for integer field") {
+			t.Errorf("Expected float value error, Got: %v", err)
+		}
+	}
+}
+
+func TestIntegerInvalidNotFloat(t *testing.T) {
+	for _, value := range []string{"none", "eight", "0xE"} {
+		setArgs(t)
+		t.Setenv("NUMERIC_OFFSET", value)
+
+		var config NumericConfig
+		err := envflagparser.ParseConfig(&config)
+		if err == nil {
+			t.Errorf("Expected error for invalid value %s on an integer field", value)
+			continue
+		}
+		if strings.Contains(err.Error(), "float value") {
+			t.Errorf("Expected integer parse error for %s, Got: %v", value, err)
+		}
+	}
+}
+
+func TestUnsignedIntegerRejectsFloat(t *testing.T) {
+	setArgs(t, "-port", "80.5")
+
+	var config NumericConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for float value on an unsigned integer field")
+	}
+}
+
+type Money int64
+
+type PriceConfig struct {
+	Price    Money `env:"NUMERIC_PRICE" money:"true"`
+	Discount Money `env:"NUMERIC_DISCOUNT" money:"round"`
+}
+
+func TestMoney(t *testing.T) {
+	for value, expected := range map[string]Money{"19.99": 1999, "20": 2000, "0.5": 50, "-3.10": -310} {
+		setArgs(t)
+		t.Setenv("NUMERIC_PRICE", value)
+
+		var config PriceConfig
+		if err := envflagparser.ParseConfig(&config); err != nil {
+			t.Fatalf("Error parsing %s: %v", value, err)
+		}
+		if config.Price != expected {
+			t.Errorf("Expected Price for %s: %d, Got: %d", value, expected, config.Price)
+		}
+	}
+}
+
+func TestMoneyPrecision(t *testing.T) {
+	setArgs(t)
+	t.Setenv("NUMERIC_PRICE", "19.999")
+
+	var config PriceConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for more than two decimal places")
+	}
+
+	setArgs(t)
+	t.Setenv("NUMERIC_PRICE", "1")
+	t.Setenv("NUMERIC_DISCOUNT", "19.995")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Discount != 2000 {
+		t.Errorf("Expected rounded Discount: %d, Got: %d", 2000, config.Discount)
+	}
+
+	data, err := envflagparser.MarshalEnv(&config)
+	if err != nil {
+		t.Fatalf("Error marshaling config: %v", err)
+	}
+	if !strings.Contains(string(data), "NUMERIC_DISCOUNT=20.00\n") {
+		t.Errorf("Expected amount in output, Got: %s", data)
+	}
+}
+
+type LenientConfig struct {
+	Features int  `env:"LENIENT_FEATURES" flag:"features"`
+	Workers  uint `env:"LENIENT_WORKERS"`
+	Verbose  bool `env:"LENIENT_VERBOSE"`
+}
+
+func TestLenientTypes(t *testing.T) {
+	envflagparser.LenientTypes = true
+	defer func() { envflagparser.LenientTypes = false }()
+
+	setArgs(t, "-features", "true")
+	t.Setenv("LENIENT_WORKERS", "false")
+	t.Setenv("LENIENT_VERBOSE", "2")
+
+	var config LenientConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Features != 1 {
+		t.Errorf("Expected Features: %d, Got: %d", 1, config.Features)
+	}
+	if config.Workers != 0 {
+		t.Errorf("Expected Workers: %d, Got: %d", 0, config.Workers)
+	}
+	if !config.Verbose {
+		t.Errorf("Expected Verbose: %t, Got: %t", true, config.Verbose)
+	}
+
+	t.Setenv("LENIENT_VERBOSE", "0")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Verbose {
+		t.Errorf("Expected Verbose: %t, Got: %t", false, config.Verbose)
+	}
+}
+
+func TestStrictTypes(t *testing.T) {
+	setArgs(t)
+	for key, value := range map[string]string{"LENIENT_FEATURES": "true", "LENIENT_VERBOSE": "2"} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			var config LenientConfig
+			if err := envflagparser.ParseConfig(&config); err == nil {
+				t.Errorf("Expected error for %s=%s", key, value)
+			}
+		})
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	type ModeConfig struct {
+		Mode os.FileMode `env:"FILE_MODE" flag:"mode"`
+	}
+
+	for value, expected := range map[string]os.FileMode{"0755": 0o755, "644": 0o644} {
+		setArgs(t, "-mode", value)
+		var config ModeConfig
+		if err := envflagparser.ParseConfig(&config); err != nil {
+			t.Fatalf("Error parsing config: %v", err)
+		}
+		if config.Mode != expected {
+			t.Errorf("Expected Mode: %v, Got: %v", expected, config.Mode)
+		}
+	}
+
+	setArgs(t)
+	t.Setenv("FILE_MODE", "999")
+	var config ModeConfig
+	if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), `invalid octal file mode "999"`) {
+		t.Errorf("Expected invalid octal file mode error, Got: %v", err)
+	}
+
+	config.Mode = 0o640
+	data, err := envflagparser.MarshalEnv(&config)
+	if err != nil {
+		t.Fatalf("Error marshaling config: %v", err)
+	}
+	if string(data) != "FILE_MODE=0640\n" {
+		t.Errorf("Expected output: %q, Got: %q", "FILE_MODE=0640\n", data)
+	}
+}
+
+func TestCoerceInt(t *testing.T) {
+	type CoerceConfig struct {
+		Budget int64  `env:"COERCE_BUDGET" coerce:"int"`
+		Limit  uint32 `env:"COERCE_LIMIT" coerce:"int"`
+	}
+
+	setArgs(t)
+	t.Setenv("COERCE_BUDGET", "1e9")
+	t.Setenv("COERCE_LIMIT", "2.5e3")
+	var config CoerceConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Budget != 1000000000 {
+		t.Errorf("Expected Budget: %d, Got: %d", 1000000000, config.Budget)
+	}
+	if config.Limit != 2500 {
+		t.Errorf("Expected Limit: %d, Got: %d", 2500, config.Limit)
+	}
+
+	t.Setenv("COERCE_BUDGET", "1.5e0")
+	if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), "value 1.5e0 is not a whole number") {
+		t.Errorf("Expected whole number error, Got: %v", err)
+	}
+}
+
+func TestLenientRounding(t *testing.T) {
+	envflagparser.LenientTypes = true
+	defer func() { envflagparser.LenientTypes = false }()
+
+	type RoundingConfig struct {
+		Workers int `env:"ROUNDING_WORKERS" flag:"workers"`
+	}
+
+	tests := map[envflagparser.RoundingMode]map[string]int{
+		envflagparser.RoundTruncate: {"2.5": 2, "3.5": 3, "-2.5": -2},
+		envflagparser.RoundHalfUp:   {"2.5": 3, "3.5": 4, "-2.5": -3},
+		envflagparser.RoundHalfEven: {"2.5": 2, "3.5": 4, "-2.5": -2},
+	}
+	for mode, values := range tests {
+		for value, expected := range values {
+			setArgs(t, "-workers", value)
+			var config RoundingConfig
+			if err := envflagparser.ParseWith(&config, envflagparser.Options{Rounding: mode}); err != nil {
+				t.Fatalf("Error parsing config: %v", err)
+			}
+			if config.Workers != expected {
+				t.Errorf("Expected Workers for %s with %s: %d, Got: %d", value, mode, expected, config.Workers)
+			}
+		}
+	}
+}
+
+func TestRoundingTag(t *testing.T) {
+	envflagparser.LenientTypes = true
+	defer func() { envflagparser.LenientTypes = false }()
+
+	setArgs(t)
+	t.Setenv("ROUNDING_SHARDS", "2.5")
+	var config struct {
+		Shards int `env:"ROUNDING_SHARDS" rounding:"halfup"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Shards != 3 {
+		t.Errorf("Expected Shards: %d, Got: %d", 3, config.Shards)
+	}
+
+	envflagparser.LenientTypes = false
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for a float value without LenientTypes")
+	}
+}
+
+func TestRoundingPrecedence(t *testing.T) {
+	envflagparser.LenientTypes = true
+	defer func() { envflagparser.LenientTypes = false }()
+	envflagparser.FloatRounding = envflagparser.RoundHalfUp
+	defer func() { envflagparser.FloatRounding = envflagparser.RoundTruncate }()
+
+	setArgs(t)
+	t.Setenv("PRECEDENCE_WORKERS", "2.5")
+	t.Setenv("PRECEDENCE_SHARDS", "2.5")
+	type PrecedenceConfig struct {
+		Workers int `env:"PRECEDENCE_WORKERS"`
+		Shards  int `env:"PRECEDENCE_SHARDS" rounding:"halfeven"`
+	}
+
+	tests := map[envflagparser.RoundingMode]PrecedenceConfig{
+		envflagparser.RoundDefault:  {Workers: 3, Shards: 2},
+		envflagparser.RoundTruncate: {Workers: 2, Shards: 2},
+		envflagparser.RoundHalfUp:   {Workers: 3, Shards: 2},
+	}
+	for mode, expected := range tests {
+		var config PrecedenceConfig
+		if err := envflagparser.ParseWith(&config, envflagparser.Options{Rounding: mode}); err != nil {
+			t.Fatalf("Error parsing config: %v", err)
+		}
+		if config != expected {
+			t.Errorf("Expected config with %s: %+v, Got: %+v", mode, expected, config)
+		}
+	}
+}
+
+func TestRoundingOptionNested(t *testing.T) {
+	envflagparser.LenientTypes = true
+	defer func() { envflagparser.LenientTypes = false }()
+
+	setArgs(t)
+	t.Setenv("NESTED_RETRIES", "2.5")
+	t.Setenv("NESTED_SIZES", "2.5,3.5")
+	t.Setenv("NESTED_LIMITS", "cpu=1.5")
+	var config struct {
+		Retries *int           `env:"NESTED_RETRIES"`
+		Sizes   []int          `env:"NESTED_SIZES"`
+		Limits  map[string]int `env:"NESTED_LIMITS"`
+	}
+	if err := envflagparser.ParseWith(&config, envflagparser.Options{Rounding: envflagparser.RoundHalfUp}); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Retries == nil || *config.Retries != 3 {
+		t.Errorf("Expected Retries: %d, Got: %v", 3, config.Retries)
+	}
+	if expected := []int{3, 4}; !reflect.DeepEqual(config.Sizes, expected) {
+		t.Errorf("Expected Sizes: %v, Got: %v", expected, config.Sizes)
+	}
+	if expected := map[string]int{"cpu": 2}; !reflect.DeepEqual(config.Limits, expected) {
+		t.Errorf("Expected Limits: %v, Got: %v", expected, config.Limits)
+	}
+}
+
+func TestLenientNegativeUnsigned(t *testing.T) {
+	envflagparser.LenientTypes = true
+	defer func() { envflagparser.LenientTypes = false }()
+
+	setArgs(t)
+	var config struct {
+		Workers uint `env:"LENIENT_WORKERS"`
+	}
+	for _, value := range []string{"-0.4", "-1"} {
+		t.Setenv("LENIENT_WORKERS", value)
+		err := envflagparser.ParseConfig(&config)
+		if err == nil || !strings.Contains(err.Error(), "negative value "+value+" for unsigned integer field") {
+			t.Errorf("Expected negative value error for %s, Got: %v", value, err)
+		}
+	}
+}
+
+func TestLenientCoerce(t *testing.T) {
+	envflagparser.LenientTypes = true
+	defer func() { envflagparser.LenientTypes = false }()
+
+	setArgs(t)
+	t.Setenv("LENIENT_BUDGET", "1.5e0")
+	var config struct {
+		Budget int64 `env:"LENIENT_BUDGET" coerce:"int"`
+	}
+	if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), "value 1.5e0 is not a whole number") {
+		t.Errorf("Expected whole number error, Got: %v", err)
+	}
+
+	t.Setenv("LENIENT_BUDGET", "1e3")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Budget != 1000 {
+		t.Errorf("Expected Budget: %d, Got: %d", 1000, config.Budget)
+	}
+}
+
+type Perms uint8
+
+func TestBitFlags(t *testing.T) {
+	type PermsConfig struct {
+		Perms Perms `env:"BITFLAGS_PERMS" flag:"perms" bitflags:"read=1,write=2,exec=4"`
+	}
+
+	setArgs(t, "-perms", "read,exec")
+	var config PermsConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Perms != 5 {
+		t.Errorf("Expected Perms: %d, Got: %d", 5, config.Perms)
+	}
+
+	data, err := envflagparser.MarshalEnv(&config)
+	if err != nil {
+		t.Fatalf("Error marshaling config: %v", err)
+	}
+	if string(data) != "BITFLAGS_PERMS=read,exec\n" {
+		t.Errorf("Expected output: %q, Got: %q", "BITFLAGS_PERMS=read,exec\n", data)
+	}
+
+	setArgs(t)
+	t.Setenv("BITFLAGS_PERMS", "read,delete")
+	expected := "unknown flag delete, expected one of read, write, exec"
+	if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected error: %s, Got: %v", expected, err)
+	}
+}
+
+func TestDecimalSeparator(t *testing.T) {
+	type LocaleConfig struct {
+		Ratio   float64   `env:"LOCALE_RATIO" decimalsep:","`
+		Budget  float64   `env:"LOCALE_BUDGET" decimalsep:"," thousandsep:"."`
+		Weights []float32 `env:"LOCALE_WEIGHTS" decimalsep:"," delim:";"`
+		Plain   float64   `env:"LOCALE_PLAIN" decimalsep:","`
+	}
+
+	setArgs(t)
+	t.Setenv("LOCALE_RATIO", "3,14")
+	t.Setenv("LOCALE_BUDGET", "1.234.567,89")
+	t.Setenv("LOCALE_WEIGHTS", "0,5;1,25")
+	t.Setenv("LOCALE_PLAIN", "2.5")
+
+	var config LocaleConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Ratio != 3.14 {
+		t.Errorf("Expected Ratio: %v, Got: %v", 3.14, config.Ratio)
+	}
+	if config.Budget != 1234567.89 {
+		t.Errorf("Expected Budget: %v, Got: %v", 1234567.89, config.Budget)
+	}
+	if expected := []float32{0.5, 1.25}; !reflect.DeepEqual(config.Weights, expected) {
+		t.Errorf("Expected Weights: %v, Got: %v", expected, config.Weights)
+	}
+	if config.Plain != 2.5 {
+		t.Errorf("Expected Plain: %v, Got: %v", 2.5, config.Plain)
+	}
+
+	data, err := envflagparser.MarshalEnv(&config)
+	if err != nil {
+		t.Fatalf("Error marshalling config: %v", err)
+	}
+	if !strings.Contains(string(data), "LOCALE_RATIO=3,14\n") {
+		t.Errorf("Expected LOCALE_RATIO=3,14 in: %s", data)
+	}
+}
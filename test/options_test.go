@@ -0,0 +1,96 @@
+package envflagparser_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type OptionsConfig struct {
+	Value string `env:"VALUE" flag:"value" default:"default"`
+	Port  int    `env:"PORT" default:"8080"`
+}
+
+func TestParseWithPrefixAndDefaults(t *testing.T) {
+	setArgs(t)
+	t.Setenv("SVC_VALUE", "env")
+	t.Setenv("PORT", "1")
+
+	var config OptionsConfig
+	err := envflagparser.ParseWith(&config, envflagparser.Options{
+		EnvPrefix: "SVC_",
+		Defaults:  map[string]string{"Port": "9090"},
+	})
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "env" {
+		t.Errorf("Expected Value: %s, Got: %s", "env", config.Value)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+}
+
+func TestParseWithPrioritiseFlags(t *testing.T) {
+	setArgs(t, "-value", "tenant:flag")
+	t.Setenv("VALUE", "env")
+
+	var config OptionsConfig
+	if err := envflagparser.ParseWith(&config, envflagparser.Options{}); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "env" {
+		t.Errorf("Expected Value: %s, Got: %s", "env", config.Value)
+	}
+
+	err := envflagparser.ParseWith(&config, envflagparser.Options{PrioritiseFlags: true, FlagValuePrefix: "tenant:"})
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "flag" {
+		t.Errorf("Expected Value: %s, Got: %s", "flag", config.Value)
+	}
+	if !envflagparser.PrioritiseEnv {
+		t.Error("Expected PrioritiseEnv to be unchanged")
+	}
+}
+
+func TestParseWithEnvFile(t *testing.T) {
+	setArgs(t)
+	path := filepath.Join(t.TempDir(), "service.env")
+	if err := os.WriteFile(path, []byte("PORT=7070\nPROT=1\n"), 0o600); err != nil {
+		t.Fatalf("Error writing env file: %v", err)
+	}
+
+	var config OptionsConfig
+	if err := envflagparser.ParseWith(&config, envflagparser.Options{EnvFile: path}); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Port != 7070 {
+		t.Errorf("Expected Port: %d, Got: %d", 7070, config.Port)
+	}
+
+	err := envflagparser.ParseWith(&config, envflagparser.Options{EnvFile: path, StrictEnvFile: true})
+	expected := "env file has keys not mapped to any field: PROT"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error: %s, Got: %v", expected, err)
+	}
+}
+
+func TestParseWithOutput(t *testing.T) {
+	setArgs(t, "-unknown")
+
+	var output bytes.Buffer
+	var config OptionsConfig
+	if err := envflagparser.ParseWith(&config, envflagparser.Options{Output: &output}); err == nil {
+		t.Error("Expected error for an unknown flag")
+	}
+	if !strings.Contains(output.String(), "-value") {
+		t.Errorf("Expected usage information in output, Got: %s", output.String())
+	}
+}
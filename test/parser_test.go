@@ -0,0 +1,87 @@
+package envflagparser_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type LogConfig struct {
+	Port     int    `env:"LOG_PORT" flag:"port" default:"8080"`
+	Host     string `env:"LOG_HOST" default:"localhost"`
+	Password string `env:"LOG_PASSWORD" secret:"true"`
+	Region   string `env:"LOG_REGION"`
+	Database struct {
+		Name string `env:"NAME" flag:"db-name"`
+	} `prefix:"LOG_DB_"`
+}
+
+func TestLogResolved(t *testing.T) {
+	setArgs(t, "-db-name", "orders")
+	t.Setenv("LOG_HOST", "example.com")
+	t.Setenv("LOG_PASSWORD", "hunter2")
+
+	parser := envflagparser.NewParser()
+	var config LogConfig
+	if err := parser.Parse(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	var buf bytes.Buffer
+	parser.LogResolved(&config, &buf)
+
+	expected := "Database.Name=orders (flag)\n" +
+		"Host=example.com (env)\n" +
+		"Password=****** (env)\n" +
+		"Port=8080 (default)\n" +
+		"Region= (none)\n"
+	if buf.String() != expected {
+		t.Errorf("Expected output:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+func TestParserFlagValuePrefix(t *testing.T) {
+	setArgs(t, "-db-name", "tenant1:orders", "-port", "9090")
+
+	parser := envflagparser.NewParser()
+	parser.FlagValuePrefix = "tenant1:"
+	var config LogConfig
+	if err := parser.Parse(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Database.Name != "orders" {
+		t.Errorf("Expected Database.Name: %s, Got: %s", "orders", config.Database.Name)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+
+	if err := envflagparser.NewParser().Parse(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Database.Name != "tenant1:orders" {
+		t.Errorf("Expected Database.Name: %s, Got: %s", "tenant1:orders", config.Database.Name)
+	}
+}
+
+func TestParserFreeze(t *testing.T) {
+	setArgs(t)
+	t.Setenv("LOG_HOST", "example.com")
+
+	parser := envflagparser.NewParser()
+	var config LogConfig
+	if err := parser.Parse(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	parser.Freeze()
+
+	t.Setenv("LOG_HOST", "changed.example.com")
+	if err := parser.Parse(&config); !errors.Is(err, envflagparser.ErrFrozen) {
+		t.Errorf("Expected error: %v, Got: %v", envflagparser.ErrFrozen, err)
+	}
+	if config.Host != "example.com" {
+		t.Errorf("Expected Host: %s, Got: %s", "example.com", config.Host)
+	}
+}
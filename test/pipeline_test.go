@@ -0,0 +1,260 @@
+package envflagparser_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type StageConfig struct {
+	Value string `env:"STAGE_VALUE" flag:"value" default:"default"`
+}
+
+// setStages enables the given stages for the duration of the test.
+func setStages(t *testing.T, stages envflagparser.Stage, prioritiseEnv bool) {
+	t.Helper()
+	oldStages, oldPrioritiseEnv := envflagparser.Stages, envflagparser.PrioritiseEnv
+	envflagparser.Stages, envflagparser.PrioritiseEnv = stages, prioritiseEnv
+	t.Cleanup(func() {
+		envflagparser.Stages, envflagparser.PrioritiseEnv = oldStages, oldPrioritiseEnv
+	})
+}
+
+// setEnvFile writes a dotenv file and uses it for the duration of the test.
+func setEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing env file: %v", err)
+	}
+	oldEnvFile := envflagparser.EnvFile
+	envflagparser.EnvFile = path
+	t.Cleanup(func() {
+		envflagparser.EnvFile = oldEnvFile
+	})
+	return path
+}
+
+func TestPipelineStages(t *testing.T) {
+	all := []envflagparser.Stage{envflagparser.StageDefault, envflagparser.StageFile, envflagparser.StageEnv, envflagparser.StageFlag}
+
+	for _, prioritiseEnv := range []bool{false, true} {
+		order := all
+		if prioritiseEnv {
+			order = []envflagparser.Stage{envflagparser.StageDefault, envflagparser.StageFile, envflagparser.StageFlag, envflagparser.StageEnv}
+		}
+
+		for mask := 0; mask < 1<<len(all); mask++ {
+			var stages envflagparser.Stage
+			for i, stage := range all {
+				if mask&(1<<i) != 0 {
+					stages |= stage
+				}
+			}
+
+			// The last enabled stage in the pipeline order wins.
+			expected := ""
+			for _, stage := range order {
+				if stages&stage != 0 {
+					expected = stage.String()
+				}
+			}
+
+			t.Run(fmt.Sprintf("stages=%04b/prioritiseEnv=%t", mask, prioritiseEnv), func(t *testing.T) {
+				setStages(t, stages, prioritiseEnv)
+				setEnvFile(t, "STAGE_VALUE=file\n")
+				setArgs(t, "-value", "flag")
+				t.Setenv("STAGE_VALUE", "env")
+
+				var config StageConfig
+				if err := envflagparser.ParseConfig(&config); err != nil {
+					t.Fatalf("Error parsing config: %v", err)
+				}
+				if config.Value != expected {
+					t.Errorf("Expected Value: %q, Got: %q", expected, config.Value)
+				}
+			})
+		}
+	}
+}
+
+func TestPipelineMissingEnvFile(t *testing.T) {
+	setArgs(t)
+	envflagparser.EnvFile = filepath.Join(t.TempDir(), "missing.env")
+	defer func() { envflagparser.EnvFile = "" }()
+
+	var config StageConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "default" {
+		t.Errorf("Expected Value: %q, Got: %q", "default", config.Value)
+	}
+}
+
+func TestPipelineEnvFileFormat(t *testing.T) {
+	setArgs(t)
+	setEnvFile(t, "# comment\n\nexport STAGE_VALUE=\"quoted file\"\n")
+
+	var config StageConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "quoted file" {
+		t.Errorf("Expected Value: %q, Got: %q", "quoted file", config.Value)
+	}
+}
+
+func TestFlagsDisabledByEnv(t *testing.T) {
+	setStages(t, envflagparser.StageDefault|envflagparser.StageFile|envflagparser.StageEnv|envflagparser.StageFlag, false)
+	setArgs(t, "-value", "flag")
+	t.Setenv("ENVFLAG_FLAGS", "off")
+
+	var config StageConfig
+	result, err := envflagparser.RegisterAndParse(&config)
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "default" {
+		t.Errorf("Expected Value: %s, Got: %s", "default", config.Value)
+	}
+	if _, ok := result.FlagValues["value"]; ok {
+		t.Error("Expected the flag not to be registered")
+	}
+
+	t.Setenv("STAGE_VALUE", "env")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "env" {
+		t.Errorf("Expected Value: %s, Got: %s", "env", config.Value)
+	}
+}
+
+func TestFlagsEnabledByEnv(t *testing.T) {
+	setStages(t, envflagparser.StageDefault|envflagparser.StageFile|envflagparser.StageEnv|envflagparser.StageFlag, false)
+	setArgs(t, "-value", "flag")
+	t.Setenv("ENVFLAG_FLAGS", "on")
+
+	var config StageConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "flag" {
+		t.Errorf("Expected Value: %s, Got: %s", "flag", config.Value)
+	}
+}
+
+func TestStrictEnvFile(t *testing.T) {
+	setArgs(t)
+	setEnvFile(t, "STAGE_VALUE=file\nSTAGE_VALEU=typo\nUNUSED=1\n")
+	envflagparser.StrictEnvFile = true
+	defer func() { envflagparser.StrictEnvFile = false }()
+
+	var config StageConfig
+	err := envflagparser.ParseConfig(&config)
+	expected := "env file has keys not mapped to any field: STAGE_VALEU, UNUSED"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error: %s, Got: %v", expected, err)
+	}
+
+	setEnvFile(t, "STAGE_VALUE=file\n")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "file" {
+		t.Errorf("Expected Value: %s, Got: %s", "file", config.Value)
+	}
+}
+
+func TestUnsetSentinel(t *testing.T) {
+	setArgs(t)
+	setEnvFile(t, "STAGE_VALUE=__UNSET__\n")
+	envflagparser.UnsetSentinel = "__UNSET__"
+	defer func() { envflagparser.UnsetSentinel = "" }()
+
+	var config StageConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "default" {
+		t.Errorf("Expected Value: %s, Got: %s", "default", config.Value)
+	}
+
+	t.Setenv("STAGE_VALUE", "__UNSET__")
+	setArgs(t, "-value", "__UNSET__")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "default" {
+		t.Errorf("Expected Value: %s, Got: %s", "default", config.Value)
+	}
+}
+
+func TestUnsetSentinelIntFlag(t *testing.T) {
+	type SentinelPortConfig struct {
+		Port int `env:"SENTINEL_PORT" flag:"port" default:"8080"`
+	}
+
+	setArgs(t, "-port", "__UNSET__")
+	envflagparser.UnsetSentinel = "__UNSET__"
+	defer func() { envflagparser.UnsetSentinel = "" }()
+
+	var config SentinelPortConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected Port: %d, Got: %d", 8080, config.Port)
+	}
+
+	t.Setenv("SENTINEL_PORT", "9090")
+	setArgs(t, "-port", "__UNSET__")
+	config = SentinelPortConfig{}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+}
+
+func TestDotenvInlineComments(t *testing.T) {
+	type CommentConfig struct {
+		Port     string `env:"COMMENT_PORT"`
+		Callback string `env:"COMMENT_CALLBACK"`
+		Name     string `env:"COMMENT_NAME"`
+		Raw      string `env:"COMMENT_RAW"`
+	}
+
+	setArgs(t)
+	setEnvFile(t, "COMMENT_PORT=8080 # note\n"+
+		"COMMENT_CALLBACK=\"https://example.com/cb#fragment\" # quoted\n"+
+		"COMMENT_NAME='a # b'\n"+
+		"COMMENT_RAW=value#tight\n")
+
+	defer func(old bool) { envflagparser.DotenvInlineComments = old }(envflagparser.DotenvInlineComments)
+	envflagparser.DotenvInlineComments = true
+
+	var config CommentConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	expected := CommentConfig{Port: "8080", Callback: "https://example.com/cb#fragment", Name: "a # b", Raw: "value"}
+	if config != expected {
+		t.Errorf("Expected config: %+v, Got: %+v", expected, config)
+	}
+
+	// Without DotenvInlineComments, comments are part of the value.
+	envflagparser.DotenvInlineComments = false
+	config = CommentConfig{}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Port != "8080 # note" {
+		t.Errorf("Expected Port: %q, Got: %q", "8080 # note", config.Port)
+	}
+}
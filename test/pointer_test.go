@@ -0,0 +1,57 @@
+package envflagparser_test
+
+import (
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type PointerConfig struct {
+	Name    *string `env:"PTR_NAME" flag:"name"`
+	Port    *int    `env:"PTR_PORT" min:"1"`
+	Verbose *bool   `flag:"verbose"`
+}
+
+func TestPointerUnset(t *testing.T) {
+	setArgs(t)
+
+	var config PointerConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Name != nil || config.Port != nil || config.Verbose != nil {
+		t.Errorf("Expected nil pointers, Got: %v, %v, %v", config.Name, config.Port, config.Verbose)
+	}
+}
+
+func TestPointerExplicitEmpty(t *testing.T) {
+	setArgs(t)
+	t.Setenv("PTR_NAME", "")
+
+	var config PointerConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Name == nil || *config.Name != "" {
+		t.Errorf("Expected pointer to empty string, Got: %v", config.Name)
+	}
+}
+
+func TestPointerValue(t *testing.T) {
+	setArgs(t, "-name", "app", "-verbose")
+	t.Setenv("PTR_PORT", "8080")
+
+	var config PointerConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Name == nil || *config.Name != "app" {
+		t.Errorf("Expected Name: %s, Got: %v", "app", config.Name)
+	}
+	if config.Port == nil || *config.Port != 8080 {
+		t.Errorf("Expected Port: %d, Got: %v", 8080, config.Port)
+	}
+	if config.Verbose == nil || !*config.Verbose {
+		t.Errorf("Expected Verbose: %t, Got: %v", true, config.Verbose)
+	}
+}
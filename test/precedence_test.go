@@ -0,0 +1,114 @@
+package envflagparser_test
+
+import (
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type BoolConfig struct {
+	Debug   bool `env:"PREC_DEBUG" flag:"debug" default:"true"`
+	Verbose bool `env:"PREC_VERBOSE" flag:"verbose" default:"false"`
+}
+
+func parseBoolConfig(t *testing.T) BoolConfig {
+	t.Helper()
+	var config BoolConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	return config
+}
+
+func TestEnvFalseOverridesTrueDefault(t *testing.T) {
+	setArgs(t)
+	t.Setenv("PREC_DEBUG", "false")
+
+	if config := parseBoolConfig(t); config.Debug {
+		t.Errorf("Expected Debug: %t, Got: %t", false, config.Debug)
+	}
+}
+
+func TestFlagFalseOverridesTrueDefault(t *testing.T) {
+	defer func() { envflagparser.PrioritiseEnv = true }()
+	for _, prioritiseEnv := range []bool{true, false} {
+		envflagparser.PrioritiseEnv = prioritiseEnv
+		setArgs(t, "-debug=false")
+
+		if config := parseBoolConfig(t); config.Debug {
+			t.Errorf("Expected Debug with PrioritiseEnv %t: %t, Got: %t", prioritiseEnv, false, config.Debug)
+		}
+	}
+}
+
+func TestFlagDefaultDoesNotOverrideEnv(t *testing.T) {
+	envflagparser.PrioritiseEnv = false
+	defer func() { envflagparser.PrioritiseEnv = true }()
+	setArgs(t)
+	t.Setenv("PREC_VERBOSE", "true")
+
+	if config := parseBoolConfig(t); !config.Verbose {
+		t.Errorf("Expected Verbose: %t, Got: %t", true, config.Verbose)
+	}
+}
+
+func TestExplicitFlagOverridesEnv(t *testing.T) {
+	envflagparser.PrioritiseEnv = false
+	defer func() { envflagparser.PrioritiseEnv = true }()
+	setArgs(t, "-verbose=false")
+	t.Setenv("PREC_VERBOSE", "true")
+
+	if config := parseBoolConfig(t); config.Verbose {
+		t.Errorf("Expected Verbose: %t, Got: %t", false, config.Verbose)
+	}
+}
+
+func TestBoolFlagWithoutValue(t *testing.T) {
+	setArgs(t, "-verbose")
+
+	if config := parseBoolConfig(t); !config.Verbose {
+		t.Errorf("Expected Verbose: %t, Got: %t", true, config.Verbose)
+	}
+}
+
+func TestLegacyMergeKeepsEnvFalse(t *testing.T) {
+	envflagparser.ExplicitFlagsOnly = false
+	defer func() { envflagparser.ExplicitFlagsOnly = true }()
+	defer func() { envflagparser.PrioritiseEnv = true }()
+	t.Setenv("PREC_DEBUG", "false")
+
+	// The flag default is not applied although the field is zero, because it was set by the environment.
+	for _, prioritiseEnv := range []bool{true, false} {
+		envflagparser.PrioritiseEnv = prioritiseEnv
+		setArgs(t)
+
+		if config := parseBoolConfig(t); config.Debug {
+			t.Errorf("Expected Debug with PrioritiseEnv %t: %t, Got: %t", prioritiseEnv, false, config.Debug)
+		}
+	}
+
+	// An explicitly set flag still overrides the environment if PrioritiseEnv is false.
+	setArgs(t, "-debug=true")
+	if config := parseBoolConfig(t); !config.Debug {
+		t.Errorf("Expected Debug: %t, Got: %t", true, config.Debug)
+	}
+}
+
+func TestLegacyMergeAppliesFlagDefault(t *testing.T) {
+	envflagparser.ExplicitFlagsOnly = false
+	defer func() { envflagparser.ExplicitFlagsOnly = true }()
+	setArgs(t)
+
+	if config := parseBoolConfig(t); !config.Debug {
+		t.Errorf("Expected Debug: %t, Got: %t", true, config.Debug)
+	}
+}
+
+func TestInvalidFlagValue(t *testing.T) {
+	setArgs(t, "-debug=maybe")
+
+	var config BoolConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for invalid flag value")
+	}
+}
@@ -0,0 +1,122 @@
+package envflagparser_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type ProfileConfig struct {
+	Host  string `env:"PROFILE_HOST" default:"localhost" default@prod:"db.example.com"`
+	Debug bool   `env:"PROFILE_DEBUG" default:"false" default@dev:"true"`
+	Token string `env:"PROFILE_TOKEN" env@prod:"PROFILE_PROD_TOKEN" flag:"token" usage:"API token" usage@prod:"Production API token"`
+}
+
+func parseProfileConfig(t *testing.T) ProfileConfig {
+	t.Helper()
+	var config ProfileConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	return config
+}
+
+func TestProfileDev(t *testing.T) {
+	setArgs(t)
+	t.Setenv("APP_PROFILE", "dev")
+	t.Setenv("PROFILE_TOKEN", "dev-token")
+	t.Setenv("PROFILE_PROD_TOKEN", "prod-token")
+
+	config := parseProfileConfig(t)
+	if config.Host != "localhost" {
+		t.Errorf("Expected Host: %s, Got: %s", "localhost", config.Host)
+	}
+	if !config.Debug {
+		t.Errorf("Expected Debug: %t, Got: %t", true, config.Debug)
+	}
+	if config.Token != "dev-token" {
+		t.Errorf("Expected Token: %s, Got: %s", "dev-token", config.Token)
+	}
+}
+
+func TestProfileProd(t *testing.T) {
+	setArgs(t)
+	t.Setenv("APP_PROFILE", "prod")
+	t.Setenv("PROFILE_TOKEN", "dev-token")
+	t.Setenv("PROFILE_PROD_TOKEN", "prod-token")
+
+	config := parseProfileConfig(t)
+	if config.Host != "db.example.com" {
+		t.Errorf("Expected Host: %s, Got: %s", "db.example.com", config.Host)
+	}
+	if config.Debug {
+		t.Errorf("Expected Debug: %t, Got: %t", false, config.Debug)
+	}
+	if config.Token != "prod-token" {
+		t.Errorf("Expected Token: %s, Got: %s", "prod-token", config.Token)
+	}
+
+	var buf bytes.Buffer
+	if err := envflagparser.Usage(&buf, ProfileConfig{}, envflagparser.UsageFormat{}); err != nil {
+		t.Fatalf("Error writing usage: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Production API token (env PROFILE_PROD_TOKEN)") {
+		t.Errorf("Expected production usage, Got: %q", buf.String())
+	}
+}
+
+func TestProfileEnvName(t *testing.T) {
+	envflagparser.ProfileEnv = "PROFILE_NAME"
+	defer func() { envflagparser.ProfileEnv = "APP_PROFILE" }()
+	setArgs(t)
+	t.Setenv("APP_PROFILE", "dev")
+	t.Setenv("PROFILE_NAME", "prod")
+
+	if config := parseProfileConfig(t); config.Host != "db.example.com" {
+		t.Errorf("Expected Host: %s, Got: %s", "db.example.com", config.Host)
+	}
+}
+
+type EnvironmentConfig struct {
+	Addr string `env:"ENVIRONMENT_ADDR" default:":80" defaultenv:"prod=:443,dev=:8080"`
+}
+
+func TestEnvironmentDefault(t *testing.T) {
+	setArgs(t)
+
+	for environment, expected := range map[string]string{"prod": ":443", "dev": ":8080", "staging": ":80", "": ":80"} {
+		t.Setenv("APP_ENV", environment)
+		var config EnvironmentConfig
+		if err := envflagparser.ParseConfig(&config); err != nil {
+			t.Fatalf("Error parsing config: %v", err)
+		}
+		if config.Addr != expected {
+			t.Errorf("Expected Addr for %q: %s, Got: %s", environment, expected, config.Addr)
+		}
+	}
+}
+
+func TestEnvironmentOption(t *testing.T) {
+	setArgs(t)
+	t.Setenv("APP_ENV", "dev")
+	envflagparser.Environment = "prod"
+	defer func() { envflagparser.Environment = "" }()
+
+	var config EnvironmentConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Addr != ":443" {
+		t.Errorf("Expected Addr: %s, Got: %s", ":443", config.Addr)
+	}
+
+	t.Setenv("ENVIRONMENT_ADDR", ":9000")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Addr != ":9000" {
+		t.Errorf("Expected Addr: %s, Got: %s", ":9000", config.Addr)
+	}
+}
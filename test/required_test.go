@@ -0,0 +1,148 @@
+package envflagparser_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type RequiredConfig struct {
+	Token string `env:"REQ_TOKEN" flag:"token" required:"true"`
+	Host  string `env:"REQ_HOST" flag:"host" default:"localhost"`
+}
+
+func TestRequiredFieldMissing(t *testing.T) {
+	setArgs(t)
+
+	var config RequiredConfig
+	err := envflagparser.ParseConfig(&config)
+	if err == nil {
+		t.Fatal("Expected error for missing required field")
+	}
+	if !strings.Contains(err.Error(), "REQ_TOKEN") || !strings.Contains(err.Error(), "-token") {
+		t.Errorf("Expected error naming env and flag, Got: %v", err)
+	}
+}
+
+func TestRequiredFieldProvided(t *testing.T) {
+	for name, setup := range map[string]func(t *testing.T){
+		"env":  func(t *testing.T) { setArgs(t); t.Setenv("REQ_TOKEN", "secret") },
+		"flag": func(t *testing.T) { setArgs(t, "-token", "secret") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			setup(t)
+
+			var config RequiredConfig
+			if err := envflagparser.ParseConfig(&config); err != nil {
+				t.Fatalf("Error parsing config: %v", err)
+			}
+			if config.Token != "secret" {
+				t.Errorf("Expected Token: %s, Got: %s", "secret", config.Token)
+			}
+		})
+	}
+}
+
+func TestRequiredWithDefault(t *testing.T) {
+	setArgs(t)
+	t.Setenv("REQ_TOKEN", "secret")
+
+	var config struct {
+		Token string `env:"REQ_TOKEN" required:"true" default:"none"`
+	}
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for required field with default value")
+	}
+}
+
+func TestCheckRequired(t *testing.T) {
+	setArgs(t)
+	t.Setenv("CHECK_DB_HOST", "db.example.com")
+
+	var config struct {
+		Token    string `env:"CHECK_TOKEN" flag:"token" required:"true"`
+		Host     string `env:"CHECK_HOST" default:"localhost"`
+		Database struct {
+			Host     string `env:"HOST" required:"true"`
+			Password string `env:"PASSWORD" required:"true"`
+		} `prefix:"CHECK_DB_"`
+	}
+
+	missing := envflagparser.CheckRequired(&config)
+	expected := []string{"CHECK_TOKEN", "CHECK_DB_PASSWORD"}
+	if !reflect.DeepEqual(missing, expected) {
+		t.Errorf("Expected missing: %v, Got: %v", expected, missing)
+	}
+	if config.Database.Host != "" {
+		t.Errorf("Expected config not to be parsed, Got Database.Host: %s", config.Database.Host)
+	}
+}
+
+func TestParseErrorCode(t *testing.T) {
+	type ExitConfig struct {
+		Token string `env:"ERRCODE_TOKEN" required:"true" errcode:"78"`
+		Port  int    `env:"ERRCODE_PORT" max:"65535"`
+	}
+
+	setArgs(t)
+	os.Unsetenv("ERRCODE_TOKEN")
+	err := envflagparser.ParseConfig(&ExitConfig{})
+	var parseErr *envflagparser.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError, Got: %v", err)
+	}
+	if parseErr.Code != 78 || parseErr.Field != "Token" {
+		t.Errorf("Expected Code and Field: 78 Token, Got: %d %s", parseErr.Code, parseErr.Field)
+	}
+
+	// Fields without an errcode tag carry DefaultErrorCode.
+	t.Setenv("ERRCODE_TOKEN", "secret")
+	t.Setenv("ERRCODE_PORT", "70000")
+	err = envflagparser.ParseConfig(&ExitConfig{})
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError, Got: %v", err)
+	}
+	if parseErr.Code != envflagparser.DefaultErrorCode || parseErr.Field != "Port" {
+		t.Errorf("Expected Code and Field: %d Port, Got: %d %s", envflagparser.DefaultErrorCode, parseErr.Code, parseErr.Field)
+	}
+	if !strings.Contains(err.Error(), "too large") {
+		t.Errorf("Expected validation message, Got: %v", err)
+	}
+
+	var invalid struct {
+		Token string `env:"ERRCODE_TOKEN" errcode:"fatal"`
+	}
+	if err := envflagparser.ParseConfig(&invalid); err == nil || !strings.Contains(err.Error(), "invalid errcode tag") {
+		t.Errorf("Expected invalid errcode tag error, Got: %v", err)
+	}
+}
+
+func TestParseErrorCodeFlag(t *testing.T) {
+	type FlagExitConfig struct {
+		Port int    `flag:"port" errcode:"3"`
+		Name string `flag:"name"`
+	}
+
+	setArgs(t, "-name", "api", "-port", "abc")
+	err := envflagparser.ParseConfig(&FlagExitConfig{})
+	var parseErr *envflagparser.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError, Got: %v", err)
+	}
+	if parseErr.Code != 3 || parseErr.Field != "Port" {
+		t.Errorf("Expected Code and Field: 3 Port, Got: %d %s", parseErr.Code, parseErr.Field)
+	}
+	if !strings.Contains(err.Error(), `invalid value "abc" for flag -port`) {
+		t.Errorf("Expected flag error message, Got: %v", err)
+	}
+
+	// Unknown flags are not errors of a field.
+	setArgs(t, "-unknown")
+	if err := envflagparser.ParseConfig(&FlagExitConfig{}); err == nil || errors.As(err, &parseErr) {
+		t.Errorf("Expected error without ParseError, Got: %v", err)
+	}
+}
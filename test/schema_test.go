@@ -0,0 +1,73 @@
+package envflagparser_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type SchemaConfig struct {
+	Port     int           `env:"SCHEMA_PORT" flag:"port" min:"1" max:"65535" required:"true" usage:"Listen port"`
+	Level    string        `env:"SCHEMA_LEVEL" default:"info" oneof:"debug,info,warn"`
+	Timeout  time.Duration `env:"SCHEMA_TIMEOUT" default:"5s"`
+	Hosts    []string      `env:"SCHEMA_HOSTS" default:"a,b" maxitems:"3"`
+	Database struct {
+		Host string `env:"SCHEMA_DB_HOST" required:"true"`
+		TLS  bool   `env:"SCHEMA_DB_TLS" default:"true"`
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := envflagparser.JSONSchema(&SchemaConfig{})
+	if err != nil {
+		t.Fatalf("Error generating schema: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Error decoding schema: %v", err)
+	}
+	if schema["type"] != "object" {
+		t.Errorf("Expected type: %v, Got: %v", "object", schema["type"])
+	}
+	if expected := []interface{}{"Port"}; !reflect.DeepEqual(schema["required"], expected) {
+		t.Errorf("Expected required: %v, Got: %v", expected, schema["required"])
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	expected := map[string]interface{}{
+		"Port": map[string]interface{}{
+			"type": "integer", "description": "Listen port", "minimum": 1.0, "maximum": 65535.0,
+		},
+		"Level":   map[string]interface{}{"type": "string", "default": "info", "enum": []interface{}{"debug", "info", "warn"}},
+		"Timeout": map[string]interface{}{"type": "string", "default": "5s"},
+		"Hosts": map[string]interface{}{
+			"type": "array", "items": map[string]interface{}{"type": "string"}, "default": []interface{}{"a", "b"}, "maxItems": 3.0,
+		},
+		"Database": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"Host": map[string]interface{}{"type": "string"},
+				"TLS":  map[string]interface{}{"type": "boolean", "default": true},
+			},
+			"required": []interface{}{"Host"},
+		},
+	}
+	for name, property := range expected {
+		if !reflect.DeepEqual(properties[name], property) {
+			t.Errorf("Expected property %s: %v, Got: %v", name, property, properties[name])
+		}
+	}
+}
+
+func TestJSONSchemaInvalidTags(t *testing.T) {
+	var config struct {
+		Port int `env:"SCHEMA_PORT" default:"8080" required:"true"`
+	}
+	if _, err := envflagparser.JSONSchema(&config); err == nil {
+		t.Error("Expected error for a required field with a default value")
+	}
+}
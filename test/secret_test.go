@@ -0,0 +1,66 @@
+package envflagparser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type SecretConfig struct {
+	Password string `env:"SECRET_PASSWORD" secret:"true"`
+	Token    string `flag:"token" secret:"true"`
+	Plain    string `env:"SECRET_PLAIN" secret:"true"`
+	Endpoint string `env:"SECRET_ENDPOINT"`
+}
+
+func TestSecretResolver(t *testing.T) {
+	secrets := map[string]string{
+		"vault://db#password": "hunter2",
+		"vault://api#token":   "t0k3n",
+	}
+	var calls []string
+	envflagparser.RegisterSecretResolver("vault", func(ref string) (string, error) {
+		calls = append(calls, ref)
+		secret, ok := secrets[ref]
+		if !ok {
+			return "", errors.New("not found")
+		}
+		return secret, nil
+	})
+	defer envflagparser.RegisterSecretResolver("vault", nil)
+
+	setArgs(t, "-token", "vault://api#token")
+	t.Setenv("SECRET_PASSWORD", "vault://db#password")
+	t.Setenv("SECRET_PLAIN", "literal")
+	t.Setenv("SECRET_ENDPOINT", "vault://db#password")
+
+	var config SecretConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Password != "hunter2" {
+		t.Errorf("Expected Password: %q, Got: %q", "hunter2", config.Password)
+	}
+	if config.Token != "t0k3n" {
+		t.Errorf("Expected Token: %q, Got: %q", "t0k3n", config.Token)
+	}
+	if config.Plain != "literal" {
+		t.Errorf("Expected Plain: %q, Got: %q", "literal", config.Plain)
+	}
+	// Fields not tagged secret are unaffected.
+	if config.Endpoint != "vault://db#password" {
+		t.Errorf("Expected Endpoint: %q, Got: %q", "vault://db#password", config.Endpoint)
+	}
+	if len(calls) != 2 {
+		t.Errorf("Expected resolver calls: %d, Got: %v", 2, calls)
+	}
+
+	setArgs(t)
+	t.Setenv("SECRET_PASSWORD", "vault://db#missing")
+	err := envflagparser.ParseConfig(&SecretConfig{})
+	if err == nil || !strings.Contains(err.Error(), "resolve secret: not found") {
+		t.Errorf("Expected resolver error, Got: %v", err)
+	}
+}
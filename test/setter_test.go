@@ -0,0 +1,150 @@
+package envflagparser_test
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type SetterConfig struct {
+	Ports []int  `env:"SETTER_PORTS" flag:"ports"`
+	Other []int  `env:"SETTER_OTHER"`
+	Name  string `env:"SETTER_NAME"`
+}
+
+// setPortRange sets an int slice field from a range like 8000-8002.
+func setPortRange(field reflect.Value, raw string) error {
+	from, to, _ := strings.Cut(raw, "-")
+	start, err := strconv.Atoi(from)
+	if err != nil {
+		return err
+	}
+	end, err := strconv.Atoi(to)
+	if err != nil {
+		return err
+	}
+	var ports []int
+	for port := start; port <= end; port++ {
+		ports = append(ports, port)
+	}
+	field.Set(reflect.ValueOf(ports))
+	return nil
+}
+
+func TestFieldSetter(t *testing.T) {
+	envflagparser.RegisterFieldSetter("SetterConfig", "Ports", setPortRange)
+	defer envflagparser.RegisterFieldSetter("SetterConfig", "Ports", nil)
+
+	setArgs(t)
+	t.Setenv("SETTER_PORTS", "8000-8002")
+	t.Setenv("SETTER_OTHER", "1,2")
+
+	var config SetterConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if !reflect.DeepEqual(config.Ports, []int{8000, 8001, 8002}) {
+		t.Errorf("Expected Ports: %v, Got: %v", []int{8000, 8001, 8002}, config.Ports)
+	}
+	if !reflect.DeepEqual(config.Other, []int{1, 2}) {
+		t.Errorf("Expected Other: %v, Got: %v", []int{1, 2}, config.Other)
+	}
+
+	os.Unsetenv("SETTER_PORTS")
+	setArgs(t, "-ports", "9000-9001")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if !reflect.DeepEqual(config.Ports, []int{9000, 9001}) {
+		t.Errorf("Expected Ports: %v, Got: %v", []int{9000, 9001}, config.Ports)
+	}
+}
+
+func TestFieldSetterQualifiedName(t *testing.T) {
+	envflagparser.RegisterFieldSetter("envflagparser_test.SetterConfig", "Name", func(field reflect.Value, raw string) error {
+		field.SetString(strings.ToUpper(raw))
+		return nil
+	})
+	defer envflagparser.RegisterFieldSetter("envflagparser_test.SetterConfig", "Name", nil)
+
+	setArgs(t)
+	t.Setenv("SETTER_NAME", "api")
+
+	var config SetterConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Name != "API" {
+		t.Errorf("Expected Name: %s, Got: %s", "API", config.Name)
+	}
+}
+
+func TestFieldSetterError(t *testing.T) {
+	envflagparser.RegisterFieldSetter("SetterConfig", "Ports", setPortRange)
+	defer envflagparser.RegisterFieldSetter("SetterConfig", "Ports", nil)
+
+	setArgs(t)
+	t.Setenv("SETTER_PORTS", "8000-x")
+
+	var config SetterConfig
+	if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), "field Ports") {
+		t.Errorf("Expected error for field Ports, Got: %v", err)
+	}
+}
+
+// Temperature is a temperature in degrees Celsius.
+type Temperature float64
+
+// parseTemperature parses a temperature with the unit C or F, plain numbers are degrees Celsius.
+func parseTemperature(value string) (Temperature, error) {
+	number := strings.TrimRightFunc(value, unicode.IsLetter)
+	degrees, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, err
+	}
+	switch unit := value[len(number):]; unit {
+	case "", "C":
+		return Temperature(degrees), nil
+	case "F":
+		return Temperature((degrees - 32) * 5 / 9), nil
+	default:
+		return 0, fmt.Errorf("unknown temperature unit %q", unit)
+	}
+}
+
+func TestRegisterParser(t *testing.T) {
+	type ClimateConfig struct {
+		Target  Temperature   `env:"PARSER_TARGET" flag:"target"`
+		Limits  []Temperature `env:"PARSER_LIMITS"`
+		Minimum *Temperature  `env:"PARSER_MINIMUM" default:"10C"`
+	}
+	envflagparser.RegisterParser(parseTemperature)
+	defer envflagparser.RegisterParser[Temperature](nil)
+
+	setArgs(t, "-target", "77F")
+	t.Setenv("PARSER_LIMITS", "32F,100C")
+	var config ClimateConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Target != 25 {
+		t.Errorf("Expected Target: %v, Got: %v", 25, config.Target)
+	}
+	if expected := []Temperature{0, 100}; !reflect.DeepEqual(config.Limits, expected) {
+		t.Errorf("Expected Limits: %v, Got: %v", expected, config.Limits)
+	}
+	if config.Minimum == nil || *config.Minimum != 10 {
+		t.Errorf("Expected Minimum: %v, Got: %v", 10, config.Minimum)
+	}
+
+	setArgs(t, "-target", "300K")
+	if err := envflagparser.ParseConfig(&ClimateConfig{}); err == nil || !strings.Contains(err.Error(), `unknown temperature unit "K"`) {
+		t.Errorf("Expected unknown unit error, Got: %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package envflagparser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type SliceConfig struct {
+	Hosts []string `env:"SLICE_HOSTS" flag:"hosts" default:"localhost"`
+	Ports []int    `env:"SLICE_PORTS"`
+	Keys  []string `env:"SLICE_KEYS" delim:"\n"`
+	Tabs  []string `env:"SLICE_TABS" delim:"\\t"`
+}
+
+func parseSliceConfig(t *testing.T) SliceConfig {
+	t.Helper()
+	var config SliceConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	return config
+}
+
+func TestCommaSeparatedSlice(t *testing.T) {
+	setArgs(t, "-hosts", "a.example.com,b.example.com")
+	t.Setenv("SLICE_PORTS", "80,443")
+
+	config := parseSliceConfig(t)
+	if expected := []string{"a.example.com", "b.example.com"}; !reflect.DeepEqual(config.Hosts, expected) {
+		t.Errorf("Expected Hosts: %v, Got: %v", expected, config.Hosts)
+	}
+	if expected := []int{80, 443}; !reflect.DeepEqual(config.Ports, expected) {
+		t.Errorf("Expected Ports: %v, Got: %v", expected, config.Ports)
+	}
+}
+
+func TestNewlineDelimitedSlice(t *testing.T) {
+	setArgs(t)
+	t.Setenv("SLICE_KEYS", "key-a\nkey,b\nkey-c\n\n")
+	t.Setenv("SLICE_TABS", "a\tb")
+
+	config := parseSliceConfig(t)
+	if expected := []string{"key-a", "key,b", "key-c"}; !reflect.DeepEqual(config.Keys, expected) {
+		t.Errorf("Expected Keys: %q, Got: %q", expected, config.Keys)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(config.Tabs, expected) {
+		t.Errorf("Expected Tabs: %q, Got: %q", expected, config.Tabs)
+	}
+}
+
+type MatrixConfig struct {
+	Table  [][]string `env:"MATRIX_TABLE"`
+	Ranges [][]int    `env:"MATRIX_RANGES" rowdelim:"|" coldelim:"-"`
+}
+
+func TestMatrix(t *testing.T) {
+	setArgs(t)
+	t.Setenv("MATRIX_TABLE", "a,b;c,d")
+	t.Setenv("MATRIX_RANGES", "1-2|3-4")
+
+	var config MatrixConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := [][]string{{"a", "b"}, {"c", "d"}}; !reflect.DeepEqual(config.Table, expected) {
+		t.Errorf("Expected Table: %v, Got: %v", expected, config.Table)
+	}
+	if expected := [][]int{{1, 2}, {3, 4}}; !reflect.DeepEqual(config.Ranges, expected) {
+		t.Errorf("Expected Ranges: %v, Got: %v", expected, config.Ranges)
+	}
+}
+
+func TestRaggedMatrix(t *testing.T) {
+	setArgs(t)
+	t.Setenv("MATRIX_TABLE", "a,b;c")
+
+	var config MatrixConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := [][]string{{"a", "b"}, {"c"}}; !reflect.DeepEqual(config.Table, expected) {
+		t.Errorf("Expected Table: %v, Got: %v", expected, config.Table)
+	}
+
+	envflagparser.AllowRaggedRows = false
+	defer func() { envflagparser.AllowRaggedRows = true }()
+	setArgs(t)
+
+	if err := envflagparser.ParseConfig(&MatrixConfig{}); err == nil {
+		t.Error("Expected error for ragged rows")
+	}
+}
+
+type EmptyDefaultConfig struct {
+	Tags    []string `env:"EMPTYDEF_TAGS" default:"a,b" emptydefault:"true"`
+	Regions []string `env:"EMPTYDEF_REGIONS" default:"eu"`
+}
+
+func TestEmptyValueKeepsDefault(t *testing.T) {
+	setArgs(t)
+	t.Setenv("EMPTYDEF_TAGS", "")
+	t.Setenv("EMPTYDEF_REGIONS", "")
+
+	config := EmptyDefaultConfig{}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(config.Tags, expected) {
+		t.Errorf("Expected Tags: %v, Got: %v", expected, config.Tags)
+	}
+	if len(config.Regions) != 0 {
+		t.Errorf("Expected empty Regions, Got: %v", config.Regions)
+	}
+}
+
+func TestEmptyValueKeepsStructDefault(t *testing.T) {
+	t.Setenv("EMPTYDEF_TAGS", "")
+
+	config := EmptyDefaultConfig{Tags: []string{"x"}}
+	if err := envflagparser.ApplyEnv(&config); err != nil {
+		t.Fatalf("Error applying env: %v", err)
+	}
+	if expected := []string{"x"}; !reflect.DeepEqual(config.Tags, expected) {
+		t.Errorf("Expected Tags: %v, Got: %v", expected, config.Tags)
+	}
+}
+
+func TestPointerSliceFromJSON(t *testing.T) {
+	setArgs(t)
+	t.Setenv("SLICE_OPTIONAL", "[1, null, 3]")
+
+	var config struct {
+		Optional []*int `env:"SLICE_OPTIONAL"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if len(config.Optional) != 3 {
+		t.Fatalf("Expected 3 elements, Got: %v", config.Optional)
+	}
+	if config.Optional[0] == nil || *config.Optional[0] != 1 {
+		t.Errorf("Expected element 0: %d, Got: %v", 1, config.Optional[0])
+	}
+	if config.Optional[1] != nil {
+		t.Errorf("Expected element 1 to be nil, Got: %v", *config.Optional[1])
+	}
+	if config.Optional[2] == nil || *config.Optional[2] != 3 {
+		t.Errorf("Expected element 2: %d, Got: %v", 3, config.Optional[2])
+	}
+}
+
+func TestPointerSliceDelimited(t *testing.T) {
+	setArgs(t)
+	t.Setenv("SLICE_OPTIONAL", "1,2")
+
+	var config struct {
+		Optional []*int `env:"SLICE_OPTIONAL"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if len(config.Optional) != 2 || *config.Optional[0] != 1 || *config.Optional[1] != 2 {
+		t.Errorf("Expected elements: [1 2], Got: %v", config.Optional)
+	}
+}
+
+func TestNumericSliceWithSpaces(t *testing.T) {
+	setArgs(t)
+	t.Setenv("SLICE_PORTS", "80, 443, 8080")
+
+	config := parseSliceConfig(t)
+	if expected := []int{80, 443, 8080}; !reflect.DeepEqual(config.Ports, expected) {
+		t.Errorf("Expected Ports: %v, Got: %v", expected, config.Ports)
+	}
+}
+
+func TestStringSliceKeepsSpaces(t *testing.T) {
+	setArgs(t, "-hosts", "a, b")
+
+	config := parseSliceConfig(t)
+	if expected := []string{"a", " b"}; !reflect.DeepEqual(config.Hosts, expected) {
+		t.Errorf("Expected Hosts: %q, Got: %q", expected, config.Hosts)
+	}
+}
+
+func TestStripListBrackets(t *testing.T) {
+	setArgs(t)
+	t.Setenv("SLICE_HOSTS", "[a,b,c]")
+	t.Setenv("SLICE_PORTS", "[80, 443]")
+
+	var config SliceConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if !reflect.DeepEqual(config.Hosts, []string{"[a", "b", "c]"}) {
+		t.Errorf("Expected Hosts: %q, Got: %q", []string{"[a", "b", "c]"}, config.Hosts)
+	}
+
+	envflagparser.StripListBrackets = true
+	defer func() { envflagparser.StripListBrackets = false }()
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if !reflect.DeepEqual(config.Hosts, []string{"a", "b", "c"}) {
+		t.Errorf("Expected Hosts: %q, Got: %q", []string{"a", "b", "c"}, config.Hosts)
+	}
+	if !reflect.DeepEqual(config.Ports, []int{80, 443}) {
+		t.Errorf("Expected Ports: %v, Got: %v", []int{80, 443}, config.Ports)
+	}
+}
+
+func TestRanges(t *testing.T) {
+	type RangeConfig struct {
+		Ports  []int   `env:"RANGE_PORTS" ranges:"true"`
+		Shards []int64 `env:"RANGE_SHARDS" ranges:"true"`
+	}
+
+	setArgs(t)
+	t.Setenv("RANGE_PORTS", "8000-8002,9000")
+	t.Setenv("RANGE_SHARDS", "-2--1, 3 - 4")
+	var config RangeConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if !reflect.DeepEqual(config.Ports, []int{8000, 8001, 8002, 9000}) {
+		t.Errorf("Expected Ports: %v, Got: %v", []int{8000, 8001, 8002, 9000}, config.Ports)
+	}
+	if !reflect.DeepEqual(config.Shards, []int64{-2, -1, 3, 4}) {
+		t.Errorf("Expected Shards: %v, Got: %v", []int64{-2, -1, 3, 4}, config.Shards)
+	}
+
+	for _, value := range []string{"8002-8000", "a-b", "80-"} {
+		t.Setenv("RANGE_PORTS", value)
+		if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), "invalid range "+value) {
+			t.Errorf("Expected invalid range error for %s, Got: %v", value, err)
+		}
+	}
+}
+
+func TestCSVFormat(t *testing.T) {
+	type TableConfig struct {
+		Rows [][]string `env:"CSV_ROWS" format:"csv"`
+	}
+
+	setArgs(t)
+	t.Setenv("CSV_ROWS", "name,address\n\"Doe, Jane\",\"1 Main St\nSpringfield\"\n\"say \"\"hi\"\"\",x")
+	var config TableConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	expected := [][]string{{"name", "address"}, {"Doe, Jane", "1 Main St\nSpringfield"}, {`say "hi"`, "x"}}
+	if !reflect.DeepEqual(config.Rows, expected) {
+		t.Errorf("Expected Rows: %q, Got: %q", expected, config.Rows)
+	}
+
+	t.Setenv("CSV_ROWS", "a,\"b")
+	if err := envflagparser.ParseConfig(&TableConfig{}); err == nil || !strings.Contains(err.Error(), "invalid csv") {
+		t.Errorf("Expected csv error, Got: %v", err)
+	}
+}
+
+func TestInterfaceSlice(t *testing.T) {
+	type BlobConfig struct {
+		Values []interface{} `env:"BLOB_VALUES"`
+		Extras []any         `env:"BLOB_EXTRAS"`
+	}
+
+	setArgs(t)
+	t.Setenv("BLOB_VALUES", `[1,"a",true]`)
+	t.Setenv("BLOB_EXTRAS", `[null, [2], {"k": "v"}]`)
+	var config BlobConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := []interface{}{1.0, "a", true}; !reflect.DeepEqual(config.Values, expected) {
+		t.Errorf("Expected Values: %#v, Got: %#v", expected, config.Values)
+	}
+	if expected := []any{nil, []any{2.0}, map[string]any{"k": "v"}}; !reflect.DeepEqual(config.Extras, expected) {
+		t.Errorf("Expected Extras: %#v, Got: %#v", expected, config.Extras)
+	}
+
+	data, err := envflagparser.MarshalEnv(&config)
+	if err != nil {
+		t.Fatalf("Error marshalling config: %v", err)
+	}
+	if !strings.Contains(string(data), `BLOB_VALUES=[1,"a",true]`) {
+		t.Errorf("Expected BLOB_VALUES as JSON array in: %s", data)
+	}
+
+	t.Setenv("BLOB_VALUES", "a,b")
+	if err := envflagparser.ParseConfig(&BlobConfig{}); err == nil || !strings.Contains(err.Error(), "parsed from a JSON array") {
+		t.Errorf("Expected JSON array error, Got: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package envflagparser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type CustomTagConfig struct {
+	Host string `config:"TAGS_HOST" cli:"host" default:"localhost"`
+	Port int    `config:"TAGS_PORT" cli:"port" fallback:"8080"`
+}
+
+func TestCustomTagNames(t *testing.T) {
+	envflagparser.Tags = envflagparser.TagNames{Env: "config", Flag: "cli", Default: "fallback"}
+	defer func() { envflagparser.Tags = envflagparser.TagNames{} }()
+	setArgs(t, "-host", "example.com")
+	t.Setenv("TAGS_PORT", "9090")
+
+	var config CustomTagConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Host != "example.com" {
+		t.Errorf("Expected Host: %s, Got: %s", "example.com", config.Host)
+	}
+	if config.Port != 9090 {
+		t.Errorf("Expected Port: %d, Got: %d", 9090, config.Port)
+	}
+}
+
+type SharedEnvConfig struct {
+	Host     string `env:"SHARED_HOST"`
+	Database struct {
+		Host string `env:"HOST"`
+	} `prefix:"SHARED_"`
+}
+
+func TestDuplicateEnvKey(t *testing.T) {
+	setArgs(t)
+
+	var config SharedEnvConfig
+	err := envflagparser.ParseConfig(&config)
+	if err == nil {
+		t.Fatal("Expected error for fields sharing an environment variable")
+	}
+	if !strings.Contains(err.Error(), "Host") || !strings.Contains(err.Error(), "Database.Host") {
+		t.Errorf("Expected error naming both fields, Got: %v", err)
+	}
+}
+
+func TestAllowSharedEnv(t *testing.T) {
+	envflagparser.AllowSharedEnv = true
+	defer func() { envflagparser.AllowSharedEnv = false }()
+	setArgs(t)
+	t.Setenv("SHARED_HOST", "example.com")
+
+	var config SharedEnvConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Host != "example.com" || config.Database.Host != "example.com" {
+		t.Errorf("Expected both hosts: %s, Got: %s and %s", "example.com", config.Host, config.Database.Host)
+	}
+}
+
+func TestUnparsableKind(t *testing.T) {
+	setArgs(t)
+
+	var config struct {
+		Events chan int `env:"UNPARSABLE_EVENTS"`
+	}
+	expected := "field Events has an env or flag tag, but fields of kind chan cannot be parsed"
+	if err := envflagparser.ParseConfig(&config); err == nil || err.Error() != expected {
+		t.Errorf("Expected error: %s, Got: %v", expected, err)
+	}
+}
+
+func TestUntaggedUnparsableKind(t *testing.T) {
+	setArgs(t)
+	t.Setenv("HOOKS_ON_CHANGE", "x")
+
+	var config struct {
+		Hooks struct {
+			OnChange func()
+			Name     string
+		} `prefix:"HOOKS_"`
+		Done chan struct{}
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package envflagparser_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type TimeConfig struct {
+	Deadline time.Time `env:"TIME_DEADLINE" flag:"deadline"`
+}
+
+func TestTimeOffset(t *testing.T) {
+	for value, offset := range map[string]time.Duration{"+2h": 2 * time.Hour, "-30m": -30 * time.Minute} {
+		setArgs(t)
+		t.Setenv("TIME_DEADLINE", value)
+
+		before := time.Now()
+		var config TimeConfig
+		if err := envflagparser.ParseConfig(&config); err != nil {
+			t.Fatalf("Error parsing config: %v", err)
+		}
+		after := time.Now()
+
+		if config.Deadline.Before(before.Add(offset)) || config.Deadline.After(after.Add(offset)) {
+			t.Errorf("Expected Deadline for %s between: %v and %v, Got: %v", value, before.Add(offset), after.Add(offset), config.Deadline)
+		}
+	}
+}
+
+func TestTimeAbsolute(t *testing.T) {
+	setArgs(t, "-deadline", "2024-05-01T12:00:00Z")
+
+	var config TimeConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	expected := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if !config.Deadline.Equal(expected) {
+		t.Errorf("Expected Deadline: %v, Got: %v", expected, config.Deadline)
+	}
+}
+
+func TestTimeInvalidOffset(t *testing.T) {
+	setArgs(t)
+	t.Setenv("TIME_DEADLINE", "+2 hours")
+
+	var config TimeConfig
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for invalid offset")
+	}
+}
+
+func TestDurationUnit(t *testing.T) {
+	setArgs(t)
+
+	type DurationConfig struct {
+		Timeout time.Duration `env:"DURATION_TIMEOUT" durationunit:"s"`
+	}
+
+	for value, expected := range map[string]time.Duration{
+		"1.5e3": 1500 * time.Second,
+		"90":    90 * time.Second,
+		"0.25":  250 * time.Millisecond,
+		"2m":    2 * time.Minute,
+	} {
+		t.Setenv("DURATION_TIMEOUT", value)
+		var config DurationConfig
+		if err := envflagparser.ParseConfig(&config); err != nil {
+			t.Fatalf("Error parsing config: %v", err)
+		}
+		if config.Timeout != expected {
+			t.Errorf("Expected Timeout for %s: %s, Got: %s", value, expected, config.Timeout)
+		}
+	}
+
+	for _, value := range []string{"1.5e", "1e400"} {
+		t.Setenv("DURATION_TIMEOUT", value)
+		var config DurationConfig
+		if err := envflagparser.ParseConfig(&config); err == nil {
+			t.Errorf("Expected error for %s", value)
+		}
+	}
+}
+
+func TestLocation(t *testing.T) {
+	type ScheduleConfig struct {
+		TZ       *time.Location `env:"SCHEDULE_TZ" flag:"tz"`
+		Fallback time.Location  `env:"SCHEDULE_FALLBACK_TZ"`
+	}
+
+	setArgs(t, "-tz", "America/New_York")
+	t.Setenv("SCHEDULE_FALLBACK_TZ", "UTC")
+	var config ScheduleConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.TZ == nil || config.TZ.String() != "America/New_York" {
+		t.Errorf("Expected TZ: %s, Got: %v", "America/New_York", config.TZ)
+	}
+	if config.Fallback.String() != "UTC" {
+		t.Errorf("Expected Fallback: %s, Got: %s", "UTC", config.Fallback.String())
+	}
+
+	setArgs(t, "-tz", "Mars/Olympus_Mons")
+	err := envflagparser.ParseConfig(&config)
+	if err == nil || !strings.Contains(err.Error(), `invalid time zone "Mars/Olympus_Mons"`) {
+		t.Errorf("Expected invalid time zone error, Got: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package envflagparser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type TOMLConfig struct {
+	Name    string        `toml:"name" default:"app"`
+	Host    string        `toml:"server.host" env:"TOML_HOST" flag:"host"`
+	Port    int           `toml:"server.port" env:"TOML_PORT"`
+	Timeout time.Duration `toml:"server.timeout"`
+	Debug   bool          `toml:"debug"`
+	Tags    []string      `toml:"tags"`
+}
+
+const tomlDocument = `
+# Service configuration
+name = "service" # inline comment
+debug = true
+tags = ["a", "b#c", 'd']
+
+[server]
+host = "toml.example.com"
+port = 8_080
+timeout = "5s"
+`
+
+func TestParseConfigFromTOML(t *testing.T) {
+	setArgs(t)
+	t.Setenv("TOML_PORT", "9090")
+
+	var config TOMLConfig
+	if err := envflagparser.ParseConfigFromTOML(&config, strings.NewReader(tomlDocument)); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	expected := TOMLConfig{
+		Name:    "service",
+		Host:    "toml.example.com",
+		Port:    9090,
+		Timeout: 5 * time.Second,
+		Debug:   true,
+		Tags:    []string{"a", "b#c", "d"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected config: %+v, Got: %+v", expected, config)
+	}
+}
+
+func TestParseConfigFromTOMLTypeMismatch(t *testing.T) {
+	setArgs(t)
+
+	var config TOMLConfig
+	err := envflagparser.ParseConfigFromTOML(&config, strings.NewReader("[server]\nport = \"8080\"\n"))
+	if err == nil || !strings.Contains(err.Error(), "server.port") {
+		t.Errorf("Expected type mismatch error naming server.port, Got: %v", err)
+	}
+}
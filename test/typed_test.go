@@ -0,0 +1,103 @@
+package envflagparser_test
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+func TestParse(t *testing.T) {
+	setArgs(t)
+	t.Setenv("STAGE_VALUE", "env")
+
+	config, err := envflagparser.Parse[StageConfig]()
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Value != "env" {
+		t.Errorf("Expected Value: %s, Got: %s", "env", config.Value)
+	}
+}
+
+func TestParseInto(t *testing.T) {
+	setArgs(t)
+	t.Setenv("STAGE_VALUE", "first")
+
+	var current atomic.Pointer[StageConfig]
+	if err := envflagparser.ParseInto(&current); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	first := current.Load()
+	if first == nil || first.Value != "first" {
+		t.Fatalf("Expected Value: %s, Got: %v", "first", first)
+	}
+
+	t.Setenv("STAGE_VALUE", "second")
+	if err := envflagparser.ParseInto(&current); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if second := current.Load(); second == first || second.Value != "second" {
+		t.Errorf("Expected a new config with Value: %s, Got: %v", "second", second)
+	}
+	if first.Value != "first" {
+		t.Errorf("Expected the previous config to be unchanged, Got: %s", first.Value)
+	}
+
+	setArgs(t, "-unknown")
+	if err := envflagparser.ParseInto(&current); err == nil {
+		t.Error("Expected error for an unknown flag")
+	}
+	if current.Load().Value != "second" {
+		t.Errorf("Expected Value to be kept: %s, Got: %s", "second", current.Load().Value)
+	}
+}
+
+type ServerDefaults struct {
+	Host    string   `env:"SEED_HOST"`
+	Port    int      `env:"SEED_PORT"`
+	Origins []string `env:"SEED_ORIGINS"`
+}
+
+func NewServerDefaults() ServerDefaults {
+	return ServerDefaults{Host: "localhost", Port: 8080, Origins: []string{"https://a.example", "https://b.example"}}
+}
+
+func TestParseFrom(t *testing.T) {
+	setArgs(t)
+	t.Setenv("SEED_PORT", "9090")
+
+	config, err := envflagparser.ParseFrom(NewServerDefaults)
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	expected := ServerDefaults{Host: "localhost", Port: 9090, Origins: []string{"https://a.example", "https://b.example"}}
+	if !reflect.DeepEqual(*config, expected) {
+		t.Errorf("Expected config: %v, Got: %v", expected, *config)
+	}
+}
+
+type DefaulterConfig struct {
+	Host string `env:"SEED_HOST"`
+	Port int    `env:"SEED_PORT" default:"8000"`
+}
+
+func (c *DefaulterConfig) SetDefaults() {
+	c.Host = "localhost"
+	c.Port = 8080
+}
+
+func TestDefaulter(t *testing.T) {
+	setArgs(t)
+	t.Setenv("SEED_HOST", "example.com")
+
+	// Values set by SetDefaults are overridden by default tags and environment variables.
+	config := DefaulterConfig{Host: "stale", Port: 1}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := (DefaulterConfig{Host: "example.com", Port: 8000}); config != expected {
+		t.Errorf("Expected config: %v, Got: %v", expected, config)
+	}
+}
@@ -0,0 +1,197 @@
+package envflagparser_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+// slowValue is a TextUnmarshaler taking longer than the test timeout.
+type slowValue struct {
+	value string
+}
+
+func (s *slowValue) UnmarshalText(text []byte) error {
+	time.Sleep(200 * time.Millisecond)
+	s.value = string(text)
+	return nil
+}
+
+func TestTextUnmarshaler(t *testing.T) {
+	setArgs(t, "-ip", "10.0.0.1")
+
+	var config struct {
+		IP net.IP `env:"UNMARSHAL_IP" flag:"ip"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if !config.IP.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("Expected IP: %s, Got: %s", "10.0.0.1", config.IP)
+	}
+}
+
+func TestUnmarshalTimeout(t *testing.T) {
+	envflagparser.UnmarshalTimeout = 10 * time.Millisecond
+	defer func() { envflagparser.UnmarshalTimeout = 0 }()
+	setArgs(t)
+	t.Setenv("UNMARSHAL_SLOW", "value")
+
+	var config struct {
+		Slow slowValue `env:"UNMARSHAL_SLOW"`
+	}
+	err := envflagparser.ParseConfig(&config)
+	if err == nil {
+		t.Fatal("Expected timeout error")
+	}
+	if !strings.Contains(err.Error(), "Slow") || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected timeout error naming the field, Got: %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error wrapping context.DeadlineExceeded, Got: %v", err)
+	}
+}
+
+func TestUnmarshalWithoutTimeout(t *testing.T) {
+	setArgs(t)
+	t.Setenv("UNMARSHAL_SLOW", "value")
+
+	var config struct {
+		Slow slowValue `env:"UNMARSHAL_SLOW"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Slow.value != "value" {
+		t.Errorf("Expected Slow: %s, Got: %s", "value", config.Slow.value)
+	}
+}
+
+// level is a config type parsing itself with SetFromString.
+type level struct {
+	name     string
+	severity int
+}
+
+func (l *level) SetFromString(value string) error {
+	switch value {
+	case "debug":
+		*l = level{name: value, severity: 0}
+	case "error":
+		*l = level{name: value, severity: 2}
+	default:
+		return fmt.Errorf("unknown level %q", value)
+	}
+	return nil
+}
+
+func TestSetFromString(t *testing.T) {
+	setArgs(t, "-level", "error")
+
+	var config struct {
+		Level level `env:"UNMARSHAL_LEVEL" flag:"level" default:"debug"`
+	}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Level.name != "error" || config.Level.severity != 2 {
+		t.Errorf("Expected Level: %s, Got: %v", "error", config.Level)
+	}
+}
+
+func TestSetFromStringError(t *testing.T) {
+	setArgs(t)
+	t.Setenv("UNMARSHAL_LEVEL", "verbose")
+
+	var config struct {
+		Level level `env:"UNMARSHAL_LEVEL"`
+	}
+	if err := envflagparser.ParseConfig(&config); err == nil {
+		t.Error("Expected error for value rejected by SetFromString")
+	}
+}
+
+func TestSlogLevel(t *testing.T) {
+	setArgs(t)
+
+	type LogConfig struct {
+		Level slog.Level `env:"LOG_LEVEL"`
+	}
+
+	for value, expected := range map[string]slog.Level{"debug": slog.LevelDebug, "INFO": slog.LevelInfo, "warn+1": slog.LevelWarn + 1} {
+		t.Setenv("LOG_LEVEL", value)
+		var config LogConfig
+		if err := envflagparser.ParseConfig(&config); err != nil {
+			t.Fatalf("Error parsing config: %v", err)
+		}
+		if config.Level != expected {
+			t.Errorf("Expected Level: %s, Got: %s", expected, config.Level)
+		}
+	}
+
+	t.Setenv("LOG_LEVEL", "verbose")
+	var config LogConfig
+	err := envflagparser.ParseConfig(&config)
+	if err == nil || !strings.Contains(err.Error(), "DEBUG, INFO, WARN and ERROR") {
+		t.Errorf("Expected error listing the accepted levels, Got: %v", err)
+	}
+}
+
+func TestHardwareAddr(t *testing.T) {
+	type MACConfig struct {
+		MAC net.HardwareAddr `env:"MAC_ADDRESS" flag:"mac"`
+	}
+	expected := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+
+	for _, value := range []string{"00:11:22:33:44:55", "00-11-22-33-44-55"} {
+		setArgs(t, "-mac", value)
+		var config MACConfig
+		if err := envflagparser.ParseConfig(&config); err != nil {
+			t.Fatalf("Error parsing config: %v", err)
+		}
+		if config.MAC.String() != expected.String() {
+			t.Errorf("Expected MAC: %s, Got: %s", expected, config.MAC)
+		}
+	}
+
+	setArgs(t)
+	t.Setenv("MAC_ADDRESS", "00:11:22:33:44")
+	var config MACConfig
+	if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), "invalid MAC address") {
+		t.Errorf("Expected invalid MAC address error, Got: %v", err)
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	type PatternConfig struct {
+		Pattern *regexp.Regexp `env:"PATTERN" flag:"pattern"`
+	}
+
+	setArgs(t, "-pattern", `^user-\d+$`)
+	var config PatternConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Pattern == nil || !config.Pattern.MatchString("user-42") || config.Pattern.MatchString("admin") {
+		t.Errorf("Expected Pattern: %s, Got: %v", `^user-\d+$`, config.Pattern)
+	}
+
+	setArgs(t)
+	t.Setenv("PATTERN", "[a-")
+	config = PatternConfig{}
+	err := envflagparser.ParseConfig(&config)
+	if err == nil || !strings.Contains(err.Error(), `invalid pattern "[a-"`) {
+		t.Errorf("Expected invalid pattern error, Got: %v", err)
+	}
+}
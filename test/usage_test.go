@@ -0,0 +1,107 @@
+package envflagparser_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type UsageConfig struct {
+	Port    int           `env:"PORT" flag:"port" default:"8080" usage:"Server port"`
+	Timeout time.Duration `flag:"timeout" usage:"Connection timeout"`
+	Hosts   []string      `flag:"hosts"`
+	Debug   bool          `flag:"debug" usage:"Enable debug logs"`
+	Secret  string        `env:"SECRET"`
+}
+
+func TestUsagePlaceholders(t *testing.T) {
+	var buf bytes.Buffer
+	if err := envflagparser.Usage(&buf, UsageConfig{}, envflagparser.UsageFormat{Prefix: "--", Separator: "="}); err != nil {
+		t.Fatalf("Error writing usage: %v", err)
+	}
+
+	usage := buf.String()
+	for _, expected := range []string{
+		"  --port=<int>\n    \tServer port (env PORT, default 8080)\n",
+		"  --timeout=<duration>\n",
+		"  --hosts=<list>\n",
+		"  --debug\n    \tEnable debug logs\n",
+	} {
+		if !strings.Contains(usage, expected) {
+			t.Errorf("Expected usage to contain: %q, Got: %q", expected, usage)
+		}
+	}
+	if strings.Contains(usage, "SECRET") {
+		t.Errorf("Expected fields without flag to be omitted, Got: %q", usage)
+	}
+	// Flags without a description are not followed by an empty description line.
+	if expected := "  --hosts=<list>\n  --debug\n"; !strings.Contains(usage, expected) {
+		t.Errorf("Expected usage to contain: %q, Got: %q", expected, usage)
+	}
+}
+
+func TestUsageDefaultFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := envflagparser.Usage(&buf, &UsageConfig{}, envflagparser.UsageFormat{}); err != nil {
+		t.Fatalf("Error writing usage: %v", err)
+	}
+
+	if expected := "  -port <int>\n"; !strings.HasPrefix(buf.String(), expected) {
+		t.Errorf("Expected usage to start with: %q, Got: %q", expected, buf.String())
+	}
+}
+
+type HiddenFlagConfig struct {
+	Port  int    `env:"HIDDEN_PORT" flag:"port" usage:"Server port"`
+	Trace string `env:"HIDDEN_TRACE" flag:"debug-trace" hiddenflag:"true" usage:"Trace target"`
+}
+
+func TestHiddenFlag(t *testing.T) {
+	setArgs(t, "-debug-trace", "db")
+	t.Setenv("HIDDEN_TRACE", "env")
+
+	var config HiddenFlagConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Trace != "env" {
+		t.Errorf("Expected env to take precedence over the hidden flag, Trace: %s, Got: %s", "env", config.Trace)
+	}
+
+	envflagparser.PrioritiseEnv = false
+	defer func() { envflagparser.PrioritiseEnv = true }()
+	setArgs(t, "-debug-trace", "db")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Trace != "db" {
+		t.Errorf("Expected Trace: %s, Got: %s", "db", config.Trace)
+	}
+}
+
+func TestHiddenFlagNotListed(t *testing.T) {
+	var buf bytes.Buffer
+	if err := envflagparser.Usage(&buf, HiddenFlagConfig{}, envflagparser.UsageFormat{}); err != nil {
+		t.Fatalf("Error writing usage: %v", err)
+	}
+	if strings.Contains(buf.String(), "debug-trace") || !strings.Contains(buf.String(), "-port") {
+		t.Errorf("Expected usage without the hidden flag, Got: %q", buf.String())
+	}
+
+	fs, err := envflagparser.FlagSet(HiddenFlagConfig{})
+	if err != nil {
+		t.Fatalf("Error creating flag set: %v", err)
+	}
+	buf.Reset()
+	fs.SetOutput(&buf)
+	fs.Usage()
+	if strings.Contains(buf.String(), "debug-trace") || !strings.Contains(buf.String(), "-port") {
+		t.Errorf("Expected flag set usage without the hidden flag, Got: %q", buf.String())
+	}
+	if fs.Lookup("debug-trace") == nil {
+		t.Error("Expected the hidden flag to be registered")
+	}
+}
@@ -0,0 +1,244 @@
+package envflagparser_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type BoundsConfig struct {
+	Timeout time.Duration `env:"BOUNDS_TIMEOUT" default:"10s" min:"1s" max:"24h"`
+	Workers int           `env:"BOUNDS_WORKERS" default:"4" min:"1" max:"64"`
+}
+
+func TestBoundsWithinRange(t *testing.T) {
+	setArgs(t)
+	t.Setenv("BOUNDS_TIMEOUT", "2h")
+
+	var config BoundsConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Timeout != 2*time.Hour {
+		t.Errorf("Expected Timeout: %s, Got: %s", 2*time.Hour, config.Timeout)
+	}
+}
+
+func TestDurationBoundsError(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected string
+	}{
+		"max": {"25h", "field Timeout too large: 25h0m0s (max 24h0m0s)"},
+		"min": {"500ms", "field Timeout too small: 500ms (min 1s)"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			setArgs(t)
+			t.Setenv("BOUNDS_TIMEOUT", test.value)
+
+			var config BoundsConfig
+			err := envflagparser.ParseConfig(&config)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected error: %q, Got: %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestIntBoundsError(t *testing.T) {
+	setArgs(t)
+	t.Setenv("BOUNDS_WORKERS", "128")
+
+	var config BoundsConfig
+	err := envflagparser.ParseConfig(&config)
+	if expected := "field Workers too large: 128 (max 64)"; err == nil || err.Error() != expected {
+		t.Errorf("Expected error: %q, Got: %v", expected, err)
+	}
+}
+
+func TestUTF8Validation(t *testing.T) {
+	type utf8Config struct {
+		Name string `env:"VALIDATE_NAME" utf8:"true"`
+	}
+
+	setArgs(t)
+	t.Setenv("VALIDATE_NAME", "Grüße")
+	var valid utf8Config
+	if err := envflagparser.ParseConfig(&valid); err != nil {
+		t.Fatalf("Error parsing valid UTF-8: %v", err)
+	}
+	if valid.Name != "Grüße" {
+		t.Errorf("Expected Name: %s, Got: %s", "Grüße", valid.Name)
+	}
+
+	setArgs(t)
+	t.Setenv("VALIDATE_NAME", "bad\xff\xfe")
+	var invalid utf8Config
+	err := envflagparser.ParseConfig(&invalid)
+	if err == nil {
+		t.Fatal("Expected error for invalid UTF-8")
+	}
+	if !strings.Contains(err.Error(), "field Name is not valid UTF-8") {
+		t.Errorf("Expected error naming the field, Got: %v", err)
+	}
+}
+
+func TestSliceElementValidation(t *testing.T) {
+	setArgs(t)
+	t.Setenv("VALIDATE_PORTS", "80,0,443")
+
+	var config struct {
+		Ports []int `env:"VALIDATE_PORTS" min:"1" max:"65535"`
+	}
+	err := envflagparser.ParseConfig(&config)
+	if err == nil {
+		t.Fatal("Expected error for element below min")
+	}
+	if !strings.Contains(err.Error(), "field Ports element 1 too small: 0 (min 1)") {
+		t.Errorf("Expected error naming the failing element, Got: %v", err)
+	}
+
+	setArgs(t)
+	t.Setenv("VALIDATE_PORTS", "80,443")
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing valid elements: %v", err)
+	}
+}
+
+func TestMaxItems(t *testing.T) {
+	type ItemsConfig struct {
+		Hosts  []string          `env:"ITEMS_HOSTS" maxitems:"2"`
+		Labels map[string]string `env:"ITEMS_LABELS" maxitems:"1"`
+	}
+
+	setArgs(t)
+	t.Setenv("ITEMS_HOSTS", "a,b")
+	t.Setenv("ITEMS_LABELS", "env=prod")
+	var config ItemsConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	t.Setenv("ITEMS_HOSTS", "a,b,c")
+	expected := "field Hosts has too many items: 3 (maxitems 2)"
+	if err := envflagparser.ParseConfig(&config); err == nil || err.Error() != expected {
+		t.Errorf("Expected error: %s, Got: %v", expected, err)
+	}
+
+	t.Setenv("ITEMS_HOSTS", "a")
+	t.Setenv("ITEMS_LABELS", "env=prod,team=core")
+	expected = "field Labels has too many items: 2 (maxitems 1)"
+	if err := envflagparser.ParseConfig(&config); err == nil || err.Error() != expected {
+		t.Errorf("Expected error: %s, Got: %v", expected, err)
+	}
+}
+
+type FormatConfig struct {
+	Email    string   `env:"FORMAT_EMAIL" format:"email"`
+	Host     string   `env:"FORMAT_HOST" format:"hostname"`
+	Endpoint string   `env:"FORMAT_ENDPOINT" format:"uri"`
+	Peers    []string `env:"FORMAT_PEERS" format:"hostname"`
+}
+
+func TestFormatValid(t *testing.T) {
+	setArgs(t)
+	t.Setenv("FORMAT_EMAIL", "ops@example.com")
+	t.Setenv("FORMAT_HOST", "db-1.example.com")
+	t.Setenv("FORMAT_ENDPOINT", "https://api.example.com/v1")
+	t.Setenv("FORMAT_PEERS", "node1,node2.local")
+
+	var config FormatConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Email != "ops@example.com" {
+		t.Errorf("Expected Email: %s, Got: %s", "ops@example.com", config.Email)
+	}
+}
+
+func TestFormatInvalid(t *testing.T) {
+	tests := map[string][2]string{
+		"missing at":      {"FORMAT_EMAIL", "ops.example.com"},
+		"display name":    {"FORMAT_EMAIL", "Ops <ops@example.com>"},
+		"underscore":      {"FORMAT_HOST", "db_1.example.com"},
+		"leading hyphen":  {"FORMAT_HOST", "-db.example.com"},
+		"relative uri":    {"FORMAT_ENDPOINT", "/v1"},
+		"invalid element": {"FORMAT_PEERS", "node1,node 2"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			setArgs(t)
+			t.Setenv(test[0], test[1])
+
+			var config FormatConfig
+			err := envflagparser.ParseConfig(&config)
+			if err == nil || !strings.Contains(err.Error(), "is not a valid") {
+				t.Errorf("Expected format error for %s, Got: %v", test[1], err)
+			}
+		})
+	}
+}
+
+func TestOneOf(t *testing.T) {
+	type OneOfConfig struct {
+		Level string `env:"ONEOF_LEVEL" oneof:"debug,info,warn"`
+		Port  int    `env:"ONEOF_PORT" oneof:"80,443"`
+	}
+
+	setArgs(t)
+	t.Setenv("ONEOF_LEVEL", "info")
+	t.Setenv("ONEOF_PORT", "443")
+	var config OneOfConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Level != "info" || config.Port != 443 {
+		t.Errorf("Expected Level and Port: info 443, Got: %s %d", config.Level, config.Port)
+	}
+
+	t.Setenv("ONEOF_LEVEL", "trace")
+	if err := envflagparser.ParseConfig(&OneOfConfig{}); err == nil || !strings.Contains(err.Error(), "is not one of debug, info, warn: trace") {
+		t.Errorf("Expected oneof error, Got: %v", err)
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+	type TLSConfig struct {
+		TLS  bool `env:"VETO_TLS"`
+		Port int  `env:"VETO_PORT"`
+	}
+
+	var order []string
+	defer envflagparser.RegisterValidator(func(config interface{}) error {
+		order = append(order, "first")
+		return nil
+	})()
+	defer envflagparser.RegisterValidator(func(config interface{}) error {
+		order = append(order, "second")
+		if c, ok := config.(*TLSConfig); ok && c.TLS && c.Port == 80 {
+			return errors.New("tls on port 80")
+		}
+		return nil
+	})()
+
+	setArgs(t)
+	t.Setenv("VETO_TLS", "true")
+	t.Setenv("VETO_PORT", "443")
+	if err := envflagparser.ParseConfig(&TLSConfig{}); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if expected := []string{"first", "second"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected order: %v, Got: %v", expected, order)
+	}
+
+	t.Setenv("VETO_PORT", "80")
+	if err := envflagparser.ParseConfig(&TLSConfig{}); err == nil || err.Error() != "tls on port 80" {
+		t.Errorf("Expected veto error, Got: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package envflagparser_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+// captureWarnings enables default mismatch warnings and captures all warnings for the duration of the test.
+func captureWarnings(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var warnings bytes.Buffer
+	oldOutput, oldMismatch := envflagparser.WarningOutput, envflagparser.WarnDefaultMismatch
+	envflagparser.WarningOutput, envflagparser.WarnDefaultMismatch = &warnings, true
+	t.Cleanup(func() {
+		envflagparser.WarningOutput, envflagparser.WarnDefaultMismatch = oldOutput, oldMismatch
+	})
+	return &warnings
+}
+
+type MismatchConfig struct {
+	Port int    `env:"MISMATCH_PORT" flag:"port" default:"8080"`
+	Host string `env:"MISMATCH_HOST" flag:"host" default:"localhost"`
+}
+
+func TestDefaultMismatchWarning(t *testing.T) {
+	warnings := captureWarnings(t)
+	setArgs(t)
+
+	config := MismatchConfig{Port: 9090, Host: "localhost"}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	output := warnings.String()
+	if !strings.Contains(output, "default 8080 of flag -port differs from initial value 9090 of field Port") {
+		t.Errorf("Expected mismatch warning for Port, Got: %q", output)
+	}
+	if strings.Contains(output, "Host") {
+		t.Errorf("Expected no warning for matching Host, Got: %q", output)
+	}
+}
+
+func TestDefaultMismatchWarningDisabled(t *testing.T) {
+	warnings := captureWarnings(t)
+	envflagparser.WarnDefaultMismatch = false
+	setArgs(t)
+
+	config := MismatchConfig{Port: 9090}
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if warnings.Len() != 0 {
+		t.Errorf("Expected no warnings, Got: %q", warnings.String())
+	}
+}
+
+type AliasConfig struct {
+	Host string `env:"ALIAS_HOST" envalias:"OLD_HOST, LEGACY_HOST"`
+	Port int    `env:"ALIAS_PORT" envalias:"OLD_PORT"`
+}
+
+func TestEnvAliasDeprecationWarning(t *testing.T) {
+	warnings := captureWarnings(t)
+	setArgs(t)
+	t.Setenv("LEGACY_HOST", "example.com")
+	t.Setenv("ALIAS_PORT", "8080")
+	t.Setenv("OLD_PORT", "9090")
+
+	var config AliasConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	if config.Host != "example.com" {
+		t.Errorf("Expected Host: %s, Got: %s", "example.com", config.Host)
+	}
+	if config.Port != 8080 {
+		t.Errorf("Expected Port: %d, Got: %d", 8080, config.Port)
+	}
+
+	output := warnings.String()
+	if !strings.Contains(output, "environment variable LEGACY_HOST of field Host is deprecated, use ALIAS_HOST instead") {
+		t.Errorf("Expected deprecation warning for LEGACY_HOST, Got: %q", output)
+	}
+	if strings.Contains(output, "OLD_PORT") {
+		t.Errorf("Expected no warning for the unused alias OLD_PORT, Got: %q", output)
+	}
+}
@@ -0,0 +1,77 @@
+package envflagparser_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type WatchConfig struct {
+	Level string `env:"WATCH_LEVEL" flag:"level" default:"info"`
+	Port  int    `env:"WATCH_PORT" default:"8080"`
+}
+
+func TestWatch(t *testing.T) {
+	oldInterval, oldDebounce := envflagparser.WatchInterval, envflagparser.WatchDebounce
+	envflagparser.WatchInterval, envflagparser.WatchDebounce = 5*time.Millisecond, 20*time.Millisecond
+	defer func() { envflagparser.WatchInterval, envflagparser.WatchDebounce = oldInterval, oldDebounce }()
+
+	path := setEnvFile(t, "WATCH_LEVEL=debug\n")
+	setArgs(t)
+
+	var config WatchConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Level != "debug" {
+		t.Fatalf("Expected Level: %s, Got: %s", "debug", config.Level)
+	}
+
+	changes := make(chan error, 10)
+	cancel := envflagparser.Watch(&config, path, func(err error) {
+		changes <- err
+	})
+	defer cancel()
+
+	// Rapid successive writes result in a single re-parse of the final content.
+	for _, content := range []string{"WATCH_LEVEL=warn\n", "WATCH_LEVEL=error\n", "WATCH_LEVEL=error\nWATCH_PORT=9090\n"} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Error writing env file: %v", err)
+		}
+	}
+
+	select {
+	case err := <-changes:
+		if err != nil {
+			t.Fatalf("Error re-parsing config: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected config to be re-parsed")
+	}
+
+	if config.Level != "error" || config.Port != 9090 {
+		t.Errorf("Expected Level: %s and Port: %d, Got: %s and %d", "error", 9090, config.Level, config.Port)
+	}
+
+	select {
+	case <-changes:
+		t.Error("Expected a single re-parse")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestParseConfigTwice(t *testing.T) {
+	setArgs(t, "-level", "warn")
+
+	for i := 0; i < 2; i++ {
+		var config WatchConfig
+		if err := envflagparser.ParseConfig(&config); err != nil {
+			t.Fatalf("Error parsing config: %v", err)
+		}
+		if config.Level != "warn" {
+			t.Errorf("Expected Level: %s, Got: %s", "warn", config.Level)
+		}
+	}
+}
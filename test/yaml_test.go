@@ -0,0 +1,78 @@
+package envflagparser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erikborsos/envflagparser"
+)
+
+type YAMLConfig struct {
+	Database struct {
+		Host    string
+		Port    int
+		Timeout time.Duration `yaml:"timeout_ms"`
+		TLS     struct {
+			Enabled bool
+			CA      string
+		}
+	} `env:"YAML_DATABASE" format:"yaml"`
+	Limits map[string]int `env:"YAML_LIMITS" format:"yaml"`
+}
+
+func TestYAMLBlock(t *testing.T) {
+	setArgs(t)
+	t.Setenv("YAML_DATABASE", "host: db.example.com\nport: 5432\n")
+	t.Setenv("YAML_LIMITS", "  # per tenant\n  cpu: 2\n  memory: 512\n")
+
+	var config YAMLConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Database.Host != "db.example.com" {
+		t.Errorf("Expected Host: %s, Got: %s", "db.example.com", config.Database.Host)
+	}
+	if config.Database.Port != 5432 {
+		t.Errorf("Expected Port: %d, Got: %d", 5432, config.Database.Port)
+	}
+	if !reflect.DeepEqual(config.Limits, map[string]int{"cpu": 2, "memory": 512}) {
+		t.Errorf("Expected Limits: %v, Got: %v", map[string]int{"cpu": 2, "memory": 512}, config.Limits)
+	}
+}
+
+func TestYAMLBlockNested(t *testing.T) {
+	setArgs(t)
+	t.Setenv("YAML_DATABASE", "host: 'db'\ntimeout_ms: 3s\ntls:\n  enabled: true\n  ca: \"/etc/ca.pem\"\n")
+
+	var config YAMLConfig
+	if err := envflagparser.ParseConfig(&config); err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	if config.Database.Timeout != 3*time.Second {
+		t.Errorf("Expected Timeout: %s, Got: %s", 3*time.Second, config.Database.Timeout)
+	}
+	if !config.Database.TLS.Enabled || config.Database.TLS.CA != "/etc/ca.pem" {
+		t.Errorf("Expected TLS: %v, Got: %v", "{true /etc/ca.pem}", config.Database.TLS)
+	}
+}
+
+func TestYAMLBlockErrors(t *testing.T) {
+	tests := map[string]string{
+		"hots: db\n":               "unknown key hots",
+		"host db\n":                "yaml line 1: expected key: value",
+		"tls:\n  a:\n    b: c\n":   "yaml line 2: nesting deeper than one level is not supported",
+		"host: db\n  port: 1\n":    "yaml line 2: unexpected indentation",
+		"port: 5432.5\n":           "key port",
+		"tls:\n  enabled: maybe\n": "key tls: key enabled",
+	}
+	for block, expected := range tests {
+		setArgs(t)
+		t.Setenv("YAML_DATABASE", block)
+		var config YAMLConfig
+		if err := envflagparser.ParseConfig(&config); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q for %q, Got: %v", expected, block, err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package envflagparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseConfigFromTOML decodes a TOML document into the provided struct, then lets environment variables
+// and flags override its values. The TOML values take the place of the dotenv file in the resolution pipeline.
+//
+// Fields are mapped by their toml tag holding the dotted key path, e.g. toml:"server.port" for the key port
+// in the [server] table. Only a subset of TOML is supported: tables, strings, integers, floats, booleans
+// and single-line arrays of these.
+func ParseConfigFromTOML(configStruct interface{}, r io.Reader) error {
+	values, err := decodeTOML(r)
+	if err != nil {
+		return err
+	}
+
+	fields, err := collectFields(reflect.ValueOf(configStruct).Elem())
+	if err != nil {
+		return err
+	}
+
+	// Check the TOML values against the field types to report mismatches by key path.
+	for _, f := range fields {
+		path := f.Tag.Get("toml")
+		value, ok := values[path]
+		if path == "" || !ok {
+			continue
+		}
+		if err := value.check(f.Type); err != nil {
+			return fmt.Errorf("toml key %s: %w", path, err)
+		}
+		if err := setValue(reflect.New(f.Type).Elem(), f.Tag, value.format(f.Tag)); err != nil {
+			return fmt.Errorf("toml key %s: %w", path, err)
+		}
+	}
+
+	_, err = registerAndParse(configStruct, parseOptions{file: func(f configField) (string, bool) {
+		value, ok := values[f.Tag.Get("toml")]
+		if !ok {
+			return "", false
+		}
+		return value.format(f.Tag), true
+	}})
+	return err
+}
+
+// tomlKind is the type of a decoded TOML value.
+type tomlKind int
+
+const (
+	tomlString tomlKind = iota
+	tomlInteger
+	tomlFloat
+	tomlBool
+	tomlArray
+)
+
+// String returns the TOML name of the kind.
+func (k tomlKind) String() string {
+	return [...]string{"string", "integer", "float", "boolean", "array"}[k]
+}
+
+// tomlValue is a decoded TOML value.
+type tomlValue struct {
+	kind  tomlKind
+	raw   string
+	items []tomlValue
+}
+
+// check reports an error if the TOML value cannot be assigned to a field of type typ.
+func (v tomlValue) check(typ reflect.Type) error {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var expected []tomlKind
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if typ == reflect.TypeOf(time.Duration(0)) {
+			expected = []tomlKind{tomlString}
+		} else {
+			expected = []tomlKind{tomlInteger}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		expected = []tomlKind{tomlInteger}
+	case reflect.Float32, reflect.Float64:
+		expected = []tomlKind{tomlFloat, tomlInteger}
+	case reflect.Bool:
+		expected = []tomlKind{tomlBool}
+	case reflect.String:
+		expected = []tomlKind{tomlString}
+	case reflect.Slice:
+		if v.kind != tomlArray {
+			return fmt.Errorf("expected array, got %s", v.kind)
+		}
+		for i, item := range v.items {
+			if err := item.check(typ.Elem()); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	for _, kind := range expected {
+		if v.kind == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("expected %s, got %s", expected[0], v.kind)
+}
+
+// format returns the value in the string form parsed by setValue, arrays are joined by the slice delimiter.
+func (v tomlValue) format(tag reflect.StructTag) string {
+	if v.kind != tomlArray {
+		return v.raw
+	}
+	items := make([]string, len(v.items))
+	for i, item := range v.items {
+		items[i] = item.raw
+	}
+	return strings.Join(items, sliceDelimiter(tag))
+}
+
+// decodeTOML decodes a TOML document into its values keyed by dotted key path.
+func decodeTOML(r io.Reader) (map[string]tomlValue, error) {
+	values := make(map[string]tomlValue)
+	table := ""
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(stripTOMLComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		// Table header
+		if strings.HasPrefix(line, "[") {
+			if strings.HasPrefix(line, "[[") || !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("toml line %d: unsupported table header %s", lineNumber, line)
+			}
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, rawValue, ok := strings.Cut(line, "=")
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("toml line %d: expected key = value", lineNumber)
+		}
+		if table != "" {
+			key = table + "." + key
+		}
+
+		value, err := decodeTOMLValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, fmt.Errorf("toml key %s: %w", key, err)
+		}
+		if _, ok := values[key]; ok {
+			return nil, fmt.Errorf("toml key %s: defined twice", key)
+		}
+		values[key] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// decodeTOMLValue decodes a single TOML value.
+func decodeTOMLValue(raw string) (tomlValue, error) {
+	switch {
+	case raw == "":
+		return tomlValue{}, fmt.Errorf("missing value")
+	case strings.HasPrefix(raw, `"`):
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return tomlValue{}, fmt.Errorf("invalid string %s", raw)
+		}
+		return tomlValue{kind: tomlString, raw: value}, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return tomlValue{}, fmt.Errorf("invalid string %s", raw)
+		}
+		return tomlValue{kind: tomlString, raw: raw[1 : len(raw)-1]}, nil
+	case raw == "true" || raw == "false":
+		return tomlValue{kind: tomlBool, raw: raw}, nil
+	case strings.HasPrefix(raw, "["):
+		if !strings.HasSuffix(raw, "]") {
+			return tomlValue{}, fmt.Errorf("unsupported multi-line array")
+		}
+		array := tomlValue{kind: tomlArray}
+		for _, item := range splitTOMLArray(raw[1 : len(raw)-1]) {
+			value, err := decodeTOMLValue(item)
+			if err != nil {
+				return tomlValue{}, err
+			}
+			array.items = append(array.items, value)
+		}
+		return array, nil
+	}
+
+	number := strings.ReplaceAll(raw, "_", "")
+	if _, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return tomlValue{kind: tomlInteger, raw: strings.TrimPrefix(number, "+")}, nil
+	}
+	if _, err := strconv.ParseFloat(number, 64); err == nil {
+		return tomlValue{kind: tomlFloat, raw: strings.TrimPrefix(number, "+")}, nil
+	}
+	return tomlValue{}, fmt.Errorf("unsupported value %s", raw)
+}
+
+// splitTOMLArray splits the items of an array by commas outside of strings, skipping a trailing comma.
+func splitTOMLArray(raw string) []string {
+	var items []string
+	var quote rune
+	start := 0
+	for i, r := range raw {
+		switch {
+		case quote != 0:
+			if r == quote && (quote == '\'' || i == 0 || raw[i-1] != '\\') {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			items = append(items, strings.TrimSpace(raw[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(raw[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items
+}
+
+// stripTOMLComment removes a # comment outside of strings from a line.
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote && (quote == '\'' || i == 0 || line[i-1] != '\\') {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,69 @@
+package envflagparser
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// transforms holds the value transforms of the transforms tag by name.
+var (
+	transformsMu sync.RWMutex
+	transforms   = map[string]func(value string) (string, error){
+		"trim": func(value string) (string, error) {
+			return strings.TrimSpace(value), nil
+		},
+		"lower": func(value string) (string, error) {
+			return strings.ToLower(value), nil
+		},
+		"upper": func(value string) (string, error) {
+			return strings.ToUpper(value), nil
+		},
+		"expandhome": expandHome,
+		"expandenv": func(value string) (string, error) {
+			return os.Expand(value, func(key string) string {
+				envValue, _ := lookupEnv(key)
+				return envValue
+			}), nil
+		},
+	}
+)
+
+// RegisterTransform registers fn as the transform name of the transforms tag, e.g. RegisterTransform("nospace", fn)
+// for transforms:"trim,nospace". The built-in transforms are trim, lower, upper, expandhome and expandenv.
+// Registering an existing name replaces its transform, registering nil removes it.
+func RegisterTransform(name string, fn func(value string) (string, error)) {
+	transformsMu.Lock()
+	defer transformsMu.Unlock()
+	if fn == nil {
+		delete(transforms, name)
+		return
+	}
+	transforms[name] = fn
+}
+
+// transformValue applies the comma-separated transforms of the transforms tag to the raw value of a field,
+// left to right, before it is converted to the field type.
+func transformValue(tag reflect.StructTag, value string) (string, error) {
+	names, ok := tag.Lookup("transforms")
+	if !ok {
+		return value, nil
+	}
+
+	transformsMu.RLock()
+	defer transformsMu.RUnlock()
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		transform, ok := transforms[name]
+		if !ok {
+			return "", fmt.Errorf("unknown transform %s", name)
+		}
+		var err error
+		if value, err = transform(value); err != nil {
+			return "", fmt.Errorf("transform %s: %w", name, err)
+		}
+	}
+	return value, nil
+}
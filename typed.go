@@ -0,0 +1,34 @@
+package envflagparser
+
+import "sync/atomic"
+
+// Parse parses configuration values like ParseConfig into a new struct of type T.
+func Parse[T any]() (*T, error) {
+	config := new(T)
+	if err := ParseConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ParseFrom parses configuration values like ParseConfig into the struct returned by newDefault, e.g. the
+// NewDefault constructor of a type that cannot implement Defaulter. Default tags, the dotenv file,
+// environment variables and flags override the values it set.
+func ParseFrom[T any](newDefault func() T) (*T, error) {
+	config := newDefault()
+	if err := ParseConfig(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// ParseInto parses a new config like Parse and atomically stores it in ptr, e.g. for lock-free hot reload
+// with readers calling ptr.Load. On errors, ptr keeps the previous config.
+func ParseInto[T any](ptr *atomic.Pointer[T]) error {
+	config, err := Parse[T]()
+	if err != nil {
+		return err
+	}
+	ptr.Store(config)
+	return nil
+}
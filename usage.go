@@ -0,0 +1,124 @@
+package envflagparser
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// UsageFormat defines the formatting of the flags written by Usage.
+// Empty values fall back to the defaults, e.g. -port <int>.
+type UsageFormat struct {
+	// Prefix is written before flag names, - by default.
+	Prefix string
+	// Separator is written between a flag name and its value placeholder, a space by default.
+	Separator string
+	// Placeholder returns the value placeholder of a field type, e.g. <duration> for time.Duration.
+	// Bool flags and empty placeholders are written without separator.
+	Placeholder func(typ reflect.Type) string
+}
+
+// Usage writes the flags of the provided struct with their value placeholders, usage information,
+// environment variables and defaults to w, e.g. --port=<int> with UsageFormat{Prefix: "--", Separator: "="}.
+// Flags tagged hiddenflag are omitted.
+func Usage(w io.Writer, configStruct interface{}, format UsageFormat) error {
+	fields, err := collectFields(reflect.Indirect(reflect.ValueOf(configStruct)))
+	if err != nil {
+		return err
+	}
+
+	defaults, err := resolveDefaults(fields, nil)
+	if err != nil {
+		return err
+	}
+
+	if format.Prefix == "" {
+		format.Prefix = "-"
+	}
+	if format.Separator == "" {
+		format.Separator = " "
+	}
+	if format.Placeholder == nil {
+		format.Placeholder = placeholder
+	}
+
+	for i, f := range fields {
+		flagName := flagTag(f.Tag)
+		if flagName == "" || f.Tag.Get("catchall") == "true" || f.Tag.Get("hiddenflag") == "true" {
+			continue
+		}
+
+		line := "  " + format.Prefix + flagName
+		if p := format.Placeholder(f.Type); p != "" && !newFieldFlag(f, "").IsBoolFlag() {
+			line += format.Separator + p
+		}
+
+		var details []string
+		if f.EnvKey != "" {
+			details = append(details, "env "+f.EnvKey)
+		}
+		if defaults[i] != "" {
+			details = append(details, "default "+defaults[i])
+		}
+		description := usageTag(f.Tag)
+		if len(details) > 0 {
+			description = strings.TrimSpace(description + " (" + strings.Join(details, ", ") + ")")
+		}
+
+		// Flags without usage, environment variable and default are written without a description line.
+		if description != "" {
+			line += "\n    \t" + description
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// placeholder returns the default value placeholder of a field type.
+func placeholder(typ reflect.Type) string {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if isAtomic(typ) {
+		typ = atomicValueType(typ)
+	}
+	if typ == reflect.TypeOf(time.Duration(0)) {
+		return "<duration>"
+	}
+	if typ == reflect.TypeOf(net.HardwareAddr(nil)) {
+		return "<mac>"
+	}
+	if typ == reflect.TypeOf(regexp.Regexp{}) {
+		return "<regexp>"
+	}
+	if typ == reflect.TypeOf(time.Location{}) {
+		return "<timezone>"
+	}
+	if typ == reflect.TypeOf(os.FileMode(0)) {
+		return "<mode>"
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "<int>"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "<uint>"
+	case reflect.Float32, reflect.Float64:
+		return "<float>"
+	case reflect.String:
+		return "<string>"
+	case reflect.Slice:
+		return "<list>"
+	case reflect.Map:
+		return "<map>"
+	}
+	return "<value>"
+}
@@ -0,0 +1,194 @@
+package envflagparser
+
+import (
+	"cmp"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// validateValue checks the value of a field against its min, max, oneof, utf8 and format tags.
+// The elements of slices are checked individually.
+func validateValue(field reflect.Value, tag reflect.StructTag) error {
+	// Pointers are validated by the value they point to.
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	// Atomic wrappers are validated by the value they hold.
+	if isAtomic(field.Type()) {
+		field = loadAtomic(field)
+	}
+
+	// Slices are validated element by element.
+	if field.Kind() == reflect.Slice {
+		for i := 0; i < field.Len(); i++ {
+			if err := validateValue(field.Index(i), tag); err != nil {
+				return fmt.Errorf("element %d %w", i, err)
+			}
+		}
+		return nil
+	}
+
+	if tag.Get("utf8") == "true" && field.Kind() == reflect.String && !utf8.ValidString(field.String()) {
+		return fmt.Errorf("is not valid UTF-8: %q", field.String())
+	}
+
+	// The yaml, csv and base64json formats are decoded by setValue, the others validate strings.
+	if format := tag.Get("format"); !isDecodedFormat(format) && field.Kind() == reflect.String {
+		if err := validateFormat(format, field.String()); err != nil {
+			return err
+		}
+	}
+
+	if options := oneOfTag(tag); len(options) > 0 {
+		if err := checkOneOf(field, options); err != nil {
+			return err
+		}
+	}
+
+	for _, bound := range []string{"min", "max"} {
+		limit, ok := tag.Lookup(bound)
+		if !ok {
+			continue
+		}
+
+		limitValue := reflect.New(field.Type()).Elem()
+		if err := setValue(limitValue, "", limit); err != nil {
+			return fmt.Errorf("has invalid %s tag %q: %w", bound, limit, err)
+		}
+
+		cmp, ok := compareValues(field, limitValue)
+		if !ok {
+			return fmt.Errorf("does not support the %s tag", bound)
+		}
+		if bound == "min" && cmp < 0 {
+			return fmt.Errorf("too small: %s (min %s)", formatValue(field), formatValue(limitValue))
+		}
+		if bound == "max" && cmp > 0 {
+			return fmt.Errorf("too large: %s (max %s)", formatValue(field), formatValue(limitValue))
+		}
+	}
+	return nil
+}
+
+// checkMaxItems checks the number of elements of a slice or map field against its maxitems tag,
+// protecting resource limits against runaway config.
+func checkMaxItems(field reflect.Value, tag reflect.StructTag) error {
+	limit, ok := tag.Lookup("maxitems")
+	if !ok {
+		return nil
+	}
+	maxItems, err := strconv.Atoi(limit)
+	if err != nil || maxItems < 0 {
+		return fmt.Errorf("has invalid maxitems tag %q", limit)
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.Slice && field.Kind() != reflect.Map {
+		return fmt.Errorf("does not support the maxitems tag")
+	}
+	if field.Len() > maxItems {
+		return fmt.Errorf("has too many items: %d (maxitems %d)", field.Len(), maxItems)
+	}
+	return nil
+}
+
+// checkOneOf checks that a value equals one of the options of the oneof tag.
+func checkOneOf(field reflect.Value, options []string) error {
+	for _, option := range options {
+		optionValue := reflect.New(field.Type()).Elem()
+		if err := setValue(optionValue, "", option); err != nil {
+			return fmt.Errorf("has invalid oneof tag option %q: %w", option, err)
+		}
+		if reflect.DeepEqual(field.Interface(), optionValue.Interface()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("is not one of %s: %s", strings.Join(options, ", "), formatValue(field))
+}
+
+// oneOfTag returns the allowed values of the comma-separated oneof tag.
+func oneOfTag(tag reflect.StructTag) []string {
+	var options []string
+	for _, option := range strings.Split(tag.Get("oneof"), ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// isDecodedFormat reports whether a format tag is decoded while parsing rather than validated, or empty.
+func isDecodedFormat(format string) bool {
+	return format == "" || format == "yaml" || format == "csv" || format == "base64json"
+}
+
+// validateFormat checks a string value against the format tag: email, hostname or uri.
+func validateFormat(format, value string) error {
+	var valid bool
+	switch format {
+	case "email":
+		address, err := mail.ParseAddress(value)
+		valid = err == nil && address.Address == value
+	case "hostname":
+		valid = isHostname(value)
+	case "uri":
+		u, err := url.Parse(value)
+		valid = err == nil && u.Scheme != ""
+	default:
+		return fmt.Errorf("has unknown format tag %q", format)
+	}
+	if !valid {
+		return fmt.Errorf("is not a valid %s: %q", format, value)
+	}
+	return nil
+}
+
+// isHostname reports whether a value is a hostname as of RFC 1123, e.g. db-1.example.com.
+func isHostname(value string) bool {
+	if value == "" || len(value) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(value, "."), ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// compareValues compares two numeric values of the same type, returning -1, 0 or 1.
+// The result is false if the values are not numeric.
+func compareValues(a, b reflect.Value) (int, bool) {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float()), true
+	}
+	return 0, false
+}
+
+// formatValue formats a value for error messages, durations and other fmt.Stringer
+// types are formatted human-readable.
+func formatValue(value reflect.Value) string {
+	return fmt.Sprint(value.Interface())
+}
@@ -0,0 +1,47 @@
+package envflagparser
+
+import "sync"
+
+// validators holds the functions registered by RegisterValidator in registration order.
+var (
+	validatorsMu sync.RWMutex
+	validators   []*validator
+)
+
+// validator is a function registered by RegisterValidator.
+type validator struct {
+	fn func(config interface{}) error
+}
+
+// RegisterValidator registers fn to check the config after each parse, e.g. to reject combinations of
+// values of a third-party type that cannot implement a method. Validators run last, in registration order,
+// and the parse returns the error of the first validator rejecting the config. Calling unregister removes fn.
+func RegisterValidator(fn func(config interface{}) error) (unregister func()) {
+	v := &validator{fn: fn}
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators = append(validators, v)
+
+	return func() {
+		validatorsMu.Lock()
+		defer validatorsMu.Unlock()
+		for i, registered := range validators {
+			if registered == v {
+				validators = append(validators[:i:i], validators[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// runValidators runs the registered validators on a parsed config, returning the first error.
+func runValidators(config interface{}) error {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+	for _, v := range validators {
+		if err := v.fn(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
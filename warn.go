@@ -0,0 +1,38 @@
+package envflagparser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// WarningOutput defines where warnings about questionable configurations are written, discarded if nil.
+var WarningOutput io.Writer = os.Stderr
+
+// WarnDefaultMismatch defines whether a warning is emitted when the initial value of a field,
+// e.g. set programmatically before parsing, differs from the default of its flag.
+var WarnDefaultMismatch = false
+
+// warnf writes a warning to WarningOutput.
+func warnf(format string, args ...interface{}) {
+	if WarningOutput != nil {
+		fmt.Fprintf(WarningOutput, "envflagparser: warning: "+format+"\n", args...)
+	}
+}
+
+// checkDefaultMismatch warns if a non-zero initial field value differs from the flag default.
+func checkDefaultMismatch(f configField, flagName, defaultValue string) {
+	if !WarnDefaultMismatch || defaultValue == "" || f.Value.IsZero() {
+		return
+	}
+
+	defaultField := reflect.New(f.Type).Elem()
+	if err := setValue(defaultField, f.Tag, defaultValue); err != nil {
+		return
+	}
+	if !reflect.DeepEqual(f.Value.Interface(), defaultField.Interface()) {
+		warnf("default %s of flag -%s differs from initial value %s of field %s",
+			formatValue(defaultField), flagName, formatValue(f.Value), f.Path)
+	}
+}
@@ -0,0 +1,63 @@
+package envflagparser
+
+import (
+	"bytes"
+	"os"
+	"time"
+)
+
+// WatchInterval defines how often Watch checks the watched file for changes.
+var WatchInterval = time.Second
+
+// WatchDebounce defines how long the watched file must be unchanged before Watch re-parses it,
+// so rapid successive writes cause a single re-parse.
+var WatchDebounce = 100 * time.Millisecond
+
+// Watch watches a dotenv file and re-parses it into the provided struct when its content changes,
+// with the file in place of EnvFile in the resolution pipeline. onChange is called with the result of each re-parse.
+// The returned function stops watching.
+//
+// The struct is written by the watching goroutine, so callers must synchronise access to it.
+func Watch(configStruct interface{}, path string, onChange func(error)) (cancel func()) {
+	done := make(chan struct{})
+	content, _ := os.ReadFile(path)
+
+	go func() {
+		ticker := time.NewTicker(WatchInterval)
+		defer ticker.Stop()
+
+		var changedAt time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				current, err := os.ReadFile(path)
+				if err == nil && !bytes.Equal(current, content) {
+					// Wait for the file to settle before re-parsing.
+					content, changedAt = current, now
+					continue
+				}
+				if changedAt.IsZero() || now.Sub(changedAt) < WatchDebounce {
+					continue
+				}
+				changedAt = time.Time{}
+				onChange(reparseFile(configStruct, path))
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// reparseFile parses the provided struct with the dotenv file at path in the file stage.
+func reparseFile(configStruct interface{}, path string) error {
+	values, err := readDotenvFile(path)
+	if err != nil {
+		return err
+	}
+	_, err = registerAndParse(configStruct, parseOptions{dotenv: values})
+	return err
+}
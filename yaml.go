@@ -0,0 +1,150 @@
+package envflagparser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// decodeYAMLBlock decodes a block of indented key: value lines, a small subset of YAML, into its values
+// by key. A key without a value starts a nested block of more indented lines, one level deep at most.
+// Anchors, lists and multi-line strings are not supported.
+func decodeYAMLBlock(block string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	var nested map[string]interface{}
+	baseIndent, nestedIndent := -1, -1
+
+	for lineNumber, line := range strings.Split(block, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indented := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(indented, "\t") {
+			return nil, fmt.Errorf("yaml line %d: tabs are not allowed for indentation", lineNumber+1)
+		}
+		indent := len(line) - len(indented)
+
+		key, raw, ok := strings.Cut(trimmed, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("yaml line %d: expected key: value", lineNumber+1)
+		}
+		raw = unquoteYAML(strings.TrimSpace(raw))
+
+		if baseIndent == -1 {
+			baseIndent = indent
+		}
+		switch {
+		case indent == baseIndent:
+			nested, nestedIndent = nil, -1
+			if raw == "" {
+				nested = make(map[string]interface{})
+				values[key] = nested
+			} else {
+				values[key] = raw
+			}
+		case indent > baseIndent && nested != nil && (nestedIndent == -1 || indent == nestedIndent):
+			if raw == "" {
+				return nil, fmt.Errorf("yaml line %d: nesting deeper than one level is not supported", lineNumber+1)
+			}
+			nestedIndent = indent
+			nested[key] = raw
+		default:
+			return nil, fmt.Errorf("yaml line %d: unexpected indentation", lineNumber+1)
+		}
+	}
+	return values, nil
+}
+
+// unquoteYAML removes the quotes of a single- or double-quoted YAML scalar.
+func unquoteYAML(raw string) string {
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		if unquoted, err := strconv.Unquote(raw); err == nil {
+			return unquoted
+		}
+	}
+	if len(raw) >= 2 && raw[0] == '\'' && raw[len(raw)-1] == '\'' {
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'")
+	}
+	return raw
+}
+
+// setYAML sets a struct or map field tagged format:"yaml" from a block of indented key: value lines.
+func setYAML(field reflect.Value, block string) error {
+	values, err := decodeYAMLBlock(block)
+	if err != nil {
+		return err
+	}
+	return setYAMLValue(field, values)
+}
+
+// setYAMLValue sets a field from a decoded YAML scalar or block. Struct fields are matched by their
+// yaml tag or case-insensitively by name.
+func setYAMLValue(field reflect.Value, value interface{}) error {
+	block, isBlock := value.(map[string]interface{})
+	if !isBlock {
+		return setValue(field, "", value.(string))
+	}
+
+	if field.Kind() == reflect.Ptr {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setYAMLValue(ptr.Elem(), block); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	keys := make([]string, 0, len(block))
+	for key := range block {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch field.Kind() {
+	case reflect.Map:
+		mapValue := reflect.MakeMapWithSize(field.Type(), len(block))
+		for _, key := range keys {
+			keyValue := reflect.New(field.Type().Key()).Elem()
+			if err := setValue(keyValue, "", key); err != nil {
+				return fmt.Errorf("key %s: %w", key, err)
+			}
+			elemValue := reflect.New(field.Type().Elem()).Elem()
+			if err := setYAMLValue(elemValue, block[key]); err != nil {
+				return fmt.Errorf("key %s: %w", key, err)
+			}
+			mapValue.SetMapIndex(keyValue, elemValue)
+		}
+		field.Set(mapValue)
+	case reflect.Struct:
+		for _, key := range keys {
+			structField, ok := yamlStructField(field.Type(), key)
+			if !ok {
+				return fmt.Errorf("unknown key %s", key)
+			}
+			if err := setYAMLValue(field.FieldByIndex(structField.Index), block[key]); err != nil {
+				return fmt.Errorf("key %s: %w", key, err)
+			}
+		}
+	default:
+		return fmt.Errorf("yaml blocks are not supported for fields of kind %s", field.Kind())
+	}
+	return nil
+}
+
+// yamlStructField returns the exported field of a struct type matching a YAML key.
+func yamlStructField(typ reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if name := f.Tag.Get("yaml"); name == key || (name == "" && strings.EqualFold(f.Name, key)) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}